@@ -4,6 +4,12 @@ Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
 package cmd
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/vars"
 	"github.com/pbidwell/hippocurl/modules/api"
 
 	"github.com/spf13/cobra"
@@ -19,16 +25,175 @@ the request with any headers or authentication you’ve defined.
 
 Example:
   hc api ServiceOne GetUser staging
+  hc api ServiceOne GetUser staging --var userId=42
+  hc api ServiceOne ListUsers staging --query page=2 --query tag=a --query tag=b
+  hc api ServiceOne GetUser staging --as-curl --redact
+  hc api ServiceOne Report staging --timeout 60s --retries 3 --retry-on network,5xx
+  hc api ServiceOne GetUser staging --output json | jq .response.json
+  hc api ServiceOne GetUser staging --snapshot check
+
+Variables referenced as {{name}} in base URLs, paths, headers and bodies are
+resolved from --var flags, then environment and service "variables" blocks.
+Process environment variables are available as {{env.NAME}}.
 
 If run without any arguments, the command enters an interactive mode, allowing you to 
 select a service, route, and environment through a guided prompt.
 
+With --output, the command runs without prompts, spinners or banners and writes
+only the result to stdout: "json" for a single JSON document with the request,
+response, timings, extracted variables and assertion results; "raw" for the
+status line, headers and body; "body" for the response body alone. Errors go to
+stderr and the exit status is 0 for 1xx-3xx responses, 4 for 4xx, 5 for 5xx,
+2 when no response was received and 1 for any other error.
+
+With --snapshot save, the response is stored as a snapshot in
+~/.hc/snapshots/<service>/<route>/<env>.json; --snapshot check compares the
+response to it, prints the differences and exits with status 6 on a mismatch.
+JSONPaths in the route's "ignore" list are skipped.
+
 This command is ideal for quickly testing or exploring API routes during development.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		ExecuteModule(api.APIModule{}, args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assignments, _ := cmd.Flags().GetStringArray("var")
+		variables, err := vars.ParseAssignments(assignments)
+		if err != nil {
+			return err
+		}
+
+		queryParams, _ := cmd.Flags().GetStringArray("query")
+		query, err := parseQuery(queryParams)
+		if err != nil {
+			return err
+		}
+
+		policy, err := policyFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		asCurl, _ := cmd.Flags().GetBool("as-curl")
+		redact, _ := cmd.Flags().GetBool("redact")
+		output, _ := cmd.Flags().GetString("output")
+		if output != "" && !slices.Contains(api.OutputFormats, output) {
+			return fmt.Errorf("invalid output format %q, expected one of %s", output, strings.Join(api.OutputFormats, ", "))
+		}
+		snapshot, _ := cmd.Flags().GetString("snapshot")
+		if snapshot != "" && !slices.Contains(api.SnapshotModes, snapshot) {
+			return fmt.Errorf("invalid snapshot mode %q, expected one of %s", snapshot, strings.Join(api.SnapshotModes, ", "))
+		}
+		if snapshot != "" && (output != "" || asCurl) {
+			return fmt.Errorf("--snapshot can't be combined with --output or --as-curl")
+		}
+
+		ExecuteModule(cmd.Context(), api.APIModule{
+			Overrides: api.Overrides{Variables: variables, Query: query, Policy: policy},
+			AsCurl:    asCurl,
+			Redact:    redact,
+			Output:    output,
+			Snapshot:  snapshot,
+		}, args)
+		return nil
 	},
 }
 
+// apiDiffCmd represents the api diff command
+var apiDiffCmd = &cobra.Command{
+	Use:   "diff <service_name> <route_name> <env_a> <env_b>",
+	Short: "Compare a route's responses in two environments",
+	Long: `The 'api diff' command sends a route to two environments at the same time and
+compares the responses: the status, selected headers (Content-Type by default)
+and a structural diff of JSON bodies, listing each differing value by its path.
+Bodies that aren't JSON are compared as text.
+
+Values that always differ, such as timestamps and ids, can be skipped with
+--ignore or the route's "ignore" list of JSONPaths; "[*]" and "*" match any
+array index or key, and ignoring a value skips everything inside it.
+
+The command exits with status 6 when differences remain after ignores.
+
+Example:
+  hc api diff ServiceOne GetUser staging production
+  hc api diff ServiceOne ListUsers staging production --ignore '$.items[*].updated_at' --header ETag`,
+	Args: cobra.ExactArgs(4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assignments, _ := cmd.Flags().GetStringArray("var")
+		variables, err := vars.ParseAssignments(assignments)
+		if err != nil {
+			return err
+		}
+		queryParams, _ := cmd.Flags().GetStringArray("query")
+		query, err := parseQuery(queryParams)
+		if err != nil {
+			return err
+		}
+		policy, err := policyFlags(cmd)
+		if err != nil {
+			return err
+		}
+		ignore, _ := cmd.Flags().GetStringArray("ignore")
+		headers, _ := cmd.Flags().GetStringArray("header")
+
+		ExecuteModule(cmd.Context(), api.DiffModule{
+			Overrides: api.Overrides{Variables: variables, Query: query, Policy: policy},
+			Ignore:    ignore,
+			Headers:   headers,
+		}, args)
+		return nil
+	},
+}
+
+// parseQuery parses "key=value" query parameters. Repeating a key adds
+// another value for it.
+func parseQuery(params []string) (map[string][]string, error) {
+	query := make(map[string][]string, len(params))
+	for _, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid query parameter %q, expected key=value", param)
+		}
+		query[key] = append(query[key], value)
+	}
+	return query, nil
+}
+
+// addPolicyFlags adds the flags overriding the configured timeout and
+// retry policy
+func addPolicyFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("timeout", 0, "Timeout of each attempt, e.g. 30s (default from config, or 30s)")
+	cmd.Flags().Int("retries", 0, "Retries after a failed attempt (default from config, or 0)")
+	cmd.Flags().StringSlice("retry-on", nil, "Statuses to retry, e.g. 503,5xx, and \"network\" for connection errors")
+	cmd.Flags().Duration("backoff", 0, "Delay before the first retry, doubled for each further one")
+}
+
+// policyFlags returns the policy set by the flags of addPolicyFlags
+func policyFlags(cmd *cobra.Command) (config.Policy, error) {
+	var policy config.Policy
+	policy.Timeout, _ = cmd.Flags().GetDuration("timeout")
+	policy.RetryOn, _ = cmd.Flags().GetStringSlice("retry-on")
+	policy.Backoff, _ = cmd.Flags().GetDuration("backoff")
+	if cmd.Flags().Changed("retries") {
+		retries, _ := cmd.Flags().GetInt("retries")
+		if retries < 0 {
+			return policy, fmt.Errorf("--retries must not be negative")
+		}
+		policy.Retries = &retries
+	}
+	return policy, nil
+}
+
 func init() {
 	rootCmd.AddCommand(apiCmd)
+	addPolicyFlags(apiCmd)
+	apiCmd.Flags().StringArray("var", nil, "Set a template variable (key=value), can be repeated")
+	apiCmd.Flags().StringArray("query", nil, "Set a query parameter (key=value), replacing configured values; repeat for multiple values")
+	apiCmd.Flags().Bool("as-curl", false, "Print the resolved request as a curl command instead of sending it")
+	apiCmd.Flags().Bool("redact", false, "Hide secrets such as auth headers in the printed curl command or JSON output")
+	apiCmd.Flags().String("output", "", "Write only the result to stdout for scripts: json, raw or body")
+	apiCmd.Flags().String("snapshot", "", "Save the response as a snapshot, or check it against the saved one: save or check")
+
+	apiCmd.AddCommand(apiDiffCmd)
+	addPolicyFlags(apiDiffCmd)
+	apiDiffCmd.Flags().StringArray("var", nil, "Set a template variable (key=value), can be repeated")
+	apiDiffCmd.Flags().StringArray("query", nil, "Set a query parameter (key=value), replacing configured values; repeat for multiple values")
+	apiDiffCmd.Flags().StringArray("ignore", nil, "JSONPath of a body value to skip, e.g. '$.meta.timestamp' (repeatable)")
+	apiDiffCmd.Flags().StringArray("header", nil, "Response header to compare (repeatable, default Content-Type)")
 }
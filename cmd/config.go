@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/pbidwell/hippocurl/modules/config"
+
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check and edit the API config",
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the API config files for mistakes",
+	Long: `The 'config validate' command checks the API config file, the files it
+includes and those in ~/.hc/services.d, reporting each problem with its file and
+line:
+
+  - YAML syntax errors, unknown keys (with the closest known key) and values of
+    the wrong type, such as an invalid duration
+  - missing names, base URLs and auth fields, and duplicate service, route and
+    environment names
+  - invalid HTTP methods, malformed URLs and unknown auth types
+  - {{variables}} not defined by the service, the environment or an extract
+    block, which are warnings since they may be passed with --var
+
+It exits with status 6 when there are errors, or warnings with --strict.
+
+Examples:
+  hc config validate
+  hc config validate --strict --configFilePath ./api_config.yml`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		strict, _ := cmd.Flags().GetBool("strict")
+		ExecuteModule(cmd.Context(), config.ValidateModule{Strict: strict}, args)
+	},
+}
+
+// configAddCmd represents the config add command
+var configAddCmd = &cobra.Command{
+	Use:     "add",
+	Aliases: []string{"edit"},
+	Short:   "Add, edit or delete services, environments and routes interactively",
+	Long: `The 'config add' command is a wizard that walks through creating a service,
+its environments (base URL, auth type and its settings, headers) and its routes
+(method, path, headers and body). It can also edit or delete existing services,
+environments and routes.
+
+Each change is written to the config file defining the service as soon as it is
+made, keeping the rest of the file and its comments. Settings the wizard doesn't
+ask about, such as assertions or retries, are kept when editing.
+
+Examples:
+  hc config add
+  hc config edit`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ExecuteModule(cmd.Context(), config.AddModule{}, args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd, configAddCmd)
+	configValidateCmd.Flags().Bool("strict", false, "Fail on warnings too")
+}
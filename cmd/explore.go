@@ -27,7 +27,7 @@ network assets and their exposure.`,
 			cmd.Help()
 			return
 		}
-		ExecuteModule(explore.ExploreModule{}, args)
+		ExecuteModule(cmd.Context(), explore.ExploreModule{}, args)
 	},
 }
 
@@ -0,0 +1,123 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pbidwell/hippocurl/modules/history"
+
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse and re-run previous API calls",
+	Long: `The 'history' command lists the calls made with 'hc api', newest first. Each
+entry records the resolved request, the response status, headers and body, and
+the timing breakdown. Secrets in headers, query parameters and JSON or form
+bodies are redacted before they are stored.
+
+The history is kept in ~/.hc/history.jsonl. Bodies larger than the API config's
+history.max_body_size (64 KiB by default) are truncated, and only the latest
+history.max_entries calls (1000 by default) are kept.
+
+Examples:
+  hc history
+  hc history --service UserService --status 5xx --since 24h
+  hc history --status error --since 2025-06-01 --until 2025-06-02
+  hc history show 42
+  hc history rerun 42`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := historyFilter(cmd)
+		if err != nil {
+			return err
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		ExecuteModule(cmd.Context(), history.HistoryModule{Filter: filter, Limit: limit}, args)
+		return nil
+	},
+}
+
+// historyShowCmd represents the history show command
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show the full details of a history entry",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ExecuteModule(cmd.Context(), history.HistoryModule{}, append([]string{"show"}, args...))
+	},
+}
+
+// historyRerunCmd represents the history rerun command
+var historyRerunCmd = &cobra.Command{
+	Use:   "rerun <id>",
+	Short: "Send the request of a history entry again",
+	Long: `The 'history rerun' command sends the service, route and environment of a
+history entry again with the same --var and --query overrides. The request is
+resolved from the current config, so edits made since are applied. Variables
+whose names look like secrets aren't stored and are resolved from the config.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ExecuteModule(cmd.Context(), history.HistoryModule{}, append([]string{"rerun"}, args...))
+	},
+}
+
+// historyFilter returns the filter set by the history list flags
+func historyFilter(cmd *cobra.Command) (history.Filter, error) {
+	var filter history.Filter
+	filter.Service, _ = cmd.Flags().GetString("service")
+	filter.Route, _ = cmd.Flags().GetString("route")
+	filter.Environment, _ = cmd.Flags().GetString("env")
+	filter.Status, _ = cmd.Flags().GetString("status")
+
+	var err error
+	since, _ := cmd.Flags().GetString("since")
+	if filter.Since, err = parseTimeFlag(since, false); err != nil {
+		return filter, fmt.Errorf("invalid --since: %w", err)
+	}
+	until, _ := cmd.Flags().GetString("until")
+	if filter.Until, err = parseTimeFlag(until, true); err != nil {
+		return filter, fmt.Errorf("invalid --until: %w", err)
+	}
+	return filter, nil
+}
+
+// parseTimeFlag parses a duration before now such as "24h", a date or an
+// RFC 3339 time. With endOfDay set, a date means the end of that day.
+func parseTimeFlag(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if date, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		if endOfDay {
+			date = date.AddDate(0, 0, 1)
+		}
+		return date, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration, date (2006-01-02) or RFC 3339 time", value)
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyRerunCmd)
+
+	historyCmd.Flags().String("service", "", "Only list calls to this service")
+	historyCmd.Flags().String("route", "", "Only list calls to this route")
+	historyCmd.Flags().String("env", "", "Only list calls to this environment")
+	historyCmd.Flags().String("status", "", "Only list responses with this status, e.g. 404, 5xx or 500-503; \"error\" for calls without a response")
+	historyCmd.Flags().String("since", "", "Only list calls after this time: a duration such as 24h, a date or an RFC 3339 time")
+	historyCmd.Flags().String("until", "", "Only list calls before this time, in the same formats as --since")
+	historyCmd.Flags().Int("limit", 20, "Number of most recent matching calls to list, 0 for all")
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/pbidwell/hippocurl/modules/importer"
+
+	"github.com/spf13/cobra"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import requests from other tools into your API configuration",
+	Long: `The 'import' command converts requests defined by other tools into services,
+routes and environments in your API configuration file. Existing comments and
+formatting in the file are preserved.`,
+}
+
+// importCurlCmd represents the import curl command
+var importCurlCmd = &cobra.Command{
+	Use:   "curl [curl command]",
+	Short: "Import a curl command as a route",
+	Long: `The 'import curl' command parses a curl command line (method, headers, data,
+basic auth, -G and the URL) and adds an equivalent route to a service. The URL's
+scheme and host become the base URL of an environment and the rest becomes the
+route's path and query parameters.
+
+The command can be passed as a single quoted argument, after '--', or on stdin.
+
+Examples:
+  hc import curl --service UserService 'curl -H "Accept: application/json" https://api.example.com/users'
+  hc import curl --service UserService --route create-user -- curl -X POST -d '{"name":"hippo"}' https://api.example.com/users
+  pbpaste | hc import curl --service UserService --env staging`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ExecuteModule(cmd.Context(), newImportModule(cmd), append([]string{"curl"}, args...))
+	},
+}
+
+// importPostmanCmd represents the import postman command
+var importPostmanCmd = &cobra.Command{
+	Use:   "postman <collection.json>",
+	Short: "Import a Postman v2.1 collection as a service",
+	Long: `The 'import postman' command converts a Postman v2.1 collection export into a
+service. Every request becomes a route named after its folder and request name,
+collection variables become service variables, and each Postman environment
+export passed with --environment becomes an environment.
+
+Pre-request and test scripts, form-data bodies and unsupported auth types can't
+be represented and are listed after the import. Importing into an existing
+service only adds the routes and environments it doesn't have yet.
+
+Examples:
+  hc import postman users.postman_collection.json
+  hc import postman users.postman_collection.json --service UserService \
+    --environment dev.postman_environment.json --environment prod.postman_environment.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ExecuteModule(cmd.Context(), newImportModule(cmd), append([]string{"postman"}, args...))
+	},
+}
+
+// importOpenAPICmd represents the import openapi command
+var importOpenAPICmd = &cobra.Command{
+	Use:   "openapi <spec>",
+	Short: "Import an OpenAPI 3 document as a service",
+	Long: `The 'import openapi' command converts an OpenAPI 3 document (YAML or JSON) into
+a service. Every operation becomes a route named after its operationId, with
+"{id}" path parameters turned into "{{id}}" variables and an example body built
+from the request schema. Every entry in 'servers' becomes an environment.
+
+Imported routes record an import_hash. Re-importing the document updates routes
+whose operation changed, adds new ones, and keeps any route edited by hand since
+the previous import.
+
+Examples:
+  hc import openapi petstore.yaml
+  hc import openapi openapi.json --service PetStore`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ExecuteModule(cmd.Context(), newImportModule(cmd), append([]string{"openapi"}, args...))
+	},
+}
+
+func newImportModule(cmd *cobra.Command) importer.ImportModule {
+	service, _ := cmd.Flags().GetString("service")
+	route, _ := cmd.Flags().GetString("route")
+	env, _ := cmd.Flags().GetString("env")
+	envFiles, _ := cmd.Flags().GetStringArray("environment")
+	return importer.ImportModule{Service: service, RouteName: route, EnvName: env, EnvironmentFiles: envFiles}
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importCurlCmd)
+
+	importCurlCmd.Flags().String("service", "", "Service to add the route to, created if it doesn't exist")
+	importCurlCmd.Flags().String("route", "", "Route name (defaults to one derived from the method and path)")
+	importCurlCmd.Flags().String("env", "default", "Environment holding the base URL, headers and auth")
+	importCurlCmd.MarkFlagRequired("service")
+
+	importCmd.AddCommand(importPostmanCmd)
+	importPostmanCmd.Flags().String("service", "", "Service name (defaults to the collection name)")
+	importPostmanCmd.Flags().StringArray("environment", nil, "Postman environment export to import (repeatable)")
+
+	importCmd.AddCommand(importOpenAPICmd)
+	importOpenAPICmd.Flags().String("service", "", "Service name (defaults to the document title)")
+}
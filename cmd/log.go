@@ -20,7 +20,7 @@ and any module-specific debug information stored during previous executions.
 Examples:
   hc log                       # View logs interactively`,
 	Run: func(cmd *cobra.Command, args []string) {
-		ExecuteModule(log.LogModule{}, args)
+		ExecuteModule(cmd.Context(), log.LogModule{}, args)
 	},
 }
 
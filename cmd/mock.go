@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package cmd
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/pbidwell/hippocurl/modules/mock"
+
+	"github.com/spf13/cobra"
+)
+
+// mockCmd represents the mock command
+var mockCmd = &cobra.Command{
+	Use:   "mock [service_name...]",
+	Short: "Serve configured routes from a local mock server",
+	Long: `The 'mock' command starts a local HTTP server answering every route of the
+given services, or of all services, with the canned response in the route's
+'mock' block: a status, headers, a body or body file, and an optional latency.
+
+Requests are matched by method and path. Placeholders such as {{id}} in a route
+path match any value in that position and can be used in the mock body. Routes
+without a mock block answer 200 with an empty body; unknown paths get a 404.
+Each request is printed and logged.
+
+Example config:
+  routes:
+    - name: get-user
+      method: GET
+      path: /users/{{id}}
+      mock:
+        status: 200
+        headers:
+          X-Mock: "true"
+        body: '{"id": "{{id}}", "name": "Hippo"}'
+        latency: 150ms
+
+Examples:
+  hc mock
+  hc mock UserService --port 9090`,
+	Run: func(cmd *cobra.Command, args []string) {
+		host, _ := cmd.Flags().GetString("host")
+		port, _ := cmd.Flags().GetInt("port")
+		ExecuteModule(cmd.Context(), mock.MockModule{Addr: net.JoinHostPort(host, strconv.Itoa(port))}, args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockCmd)
+	mockCmd.Flags().String("host", "127.0.0.1", "Address to listen on")
+	mockCmd.Flags().Int("port", 8080, "Port to listen on")
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package cmd
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/pbidwell/hippocurl/modules/record"
+
+	"github.com/spf13/cobra"
+)
+
+// recordCmd represents the record command
+var recordCmd = &cobra.Command{
+	Use:   "record --service <name> [--target <url>]",
+	Short: "Record requests sent through a local proxy as routes",
+	Long: `The 'record' command starts a local HTTP proxy and writes every new request
+sent through it as a route of the given service, so the traffic of another tool
+or app can be replayed with hc.
+
+With --target, hc runs a reverse proxy: point the client at the proxy address
+instead of the real API, and requests are forwarded to the target, which may use
+HTTPS. Without it, hc runs a forward proxy for plain HTTP: set HTTP_PROXY for the
+client. The first host seen becomes the environment's base URL; requests to other
+hosts are proxied but not recorded.
+
+Numeric and UUID path segments become {{id}} placeholders, and requests with the
+same method and path are recorded once. The Authorization header of the first
+recorded request becomes the environment's auth; cookies and client headers are
+left out. Credentials are never written to the config: the auth token or
+password, and secret-looking headers, query parameters and body fields, become
+{{variables}} to set with --var or the environment's variables. With --mock,
+responses are saved as the routes' mock blocks for 'hc mock', with secret-looking
+fields redacted. The config file is updated as requests arrive, keeping its
+comments.
+
+Examples:
+  hc record --service Users --target https://api.example.com --port 8081
+  HTTP_PROXY=http://127.0.0.1:8081 ./my-client &
+  hc record --service Users --env dev --mock`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		service, _ := cmd.Flags().GetString("service")
+		env, _ := cmd.Flags().GetString("env")
+		target, _ := cmd.Flags().GetString("target")
+		host, _ := cmd.Flags().GetString("host")
+		port, _ := cmd.Flags().GetInt("port")
+		mock, _ := cmd.Flags().GetBool("mock")
+		ExecuteModule(cmd.Context(), record.RecordModule{
+			Service: service,
+			EnvName: env,
+			Target:  target,
+			Addr:    net.JoinHostPort(host, strconv.Itoa(port)),
+			Mock:    mock,
+		}, args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+	recordCmd.Flags().String("service", "", "Service to record routes into, created if missing")
+	recordCmd.Flags().String("env", "recorded", "Environment holding the recorded base URL")
+	recordCmd.Flags().String("target", "", "Base URL to forward requests to, running a reverse proxy")
+	recordCmd.Flags().String("host", "127.0.0.1", "Address to listen on")
+	recordCmd.Flags().Int("port", 8081, "Port to listen on")
+	recordCmd.Flags().Bool("mock", false, "Also save responses as mock blocks")
+	recordCmd.MarkFlagRequired("service")
+}
@@ -4,11 +4,16 @@ Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/secrets"
 	"github.com/pbidwell/hippocurl/modules"
 	"github.com/pbidwell/hippocurl/utils"
 
@@ -29,22 +34,73 @@ var rootCmd = &cobra.Command{
 	Long:  fmt.Sprintf("%vHippoCurl (hc) is a command-line tool for exploring and interacting with HTTP and other web services.", utils.GetTitle()),
 }
 
+// Execute runs the root command with a context that is cancelled on
+// SIGINT or SIGTERM. A second signal terminates hc immediately. Errors
+// returned to cobra are bad arguments or flags, so they exit with ExitUsage.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(modules.ExitUsage)
 	}
 }
 
-func ExecuteModule(mod modules.HippoModule, args []string) {
-	cfg := config.Load()
+// configChecker is implemented by modules that run even when the API config
+// can't be read, because they report its problems themselves
+type configChecker interface {
+	ChecksConfig()
+}
+
+// ExecuteModule runs a module and exits with the status matching its
+// error, if any
+func ExecuteModule(ctx context.Context, mod modules.HippoModule, args []string) {
+	configFilePath, _ := rootCmd.PersistentFlags().GetString("configFilePath")
+	cfg := config.Load(configFilePath)
 	logger := cfg.Logger
 
+	logger.Printf("Using API config %s (%s)", cfg.APIConfigPath, cfg.APIConfigSource)
+	if verbose, _ := rootCmd.PersistentFlags().GetBool("verbose"); verbose {
+		fmt.Fprintf(os.Stderr, "Using config %s (%s)\n", cfg.APIConfigPath, cfg.APIConfigSource)
+	}
+	if _, ok := mod.(configChecker); cfg.APIConfigErr != nil && !ok {
+		logger.Printf("Error reading API config: %v", cfg.APIConfigErr)
+		fmt.Fprintf(os.Stderr, "Error: reading config: %v\nRun \"hc config validate\" for details.\n", cfg.APIConfigErr)
+		os.Exit(modules.ExitFailure)
+	}
+
 	logger.Printf("Executing module: [%s] with arguments [%s]", mod.Name(), strings.Join(args, ", "))
-	mod.Execute(cfg, args)
-	logger.Printf("Module [%s] execution complete", mod.Name())
+	err := mod.Execute(ctx, cfg, args)
+	if ctx.Err() != nil {
+		logger.Printf("Module [%s] cancelled: %v", mod.Name(), context.Cause(ctx))
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		os.Exit(modules.ExitInterrupted)
+	}
+	if err == nil {
+		logger.Printf("Module [%s] execution complete", mod.Name())
+		return
+	}
+
+	code := modules.ExitFailure
+	var exitErr *modules.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.Code
+		err = exitErr.Err
+	}
+	if err != nil {
+		logger.Printf("Module [%s] failed: %v", mod.Name(), err)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", secrets.Mask(err.Error()))
+	}
+	logger.Printf("Module [%s] exited with status %d", mod.Name(), code)
+	os.Exit(code)
 }
 
 func init() {
-	rootCmd.Flags().String("configFilePath", "", "Global config file location. Defaults to $HOME/.hc/config.yml")
+	rootCmd.PersistentFlags().String("configFilePath", "", "API config file to use. Defaults to $"+config.ConfigEnvVar+", then the nearest .hc/api_config.yml in the current directory or a parent, then $HOME/.hc/api_config.yml")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Print which config file is used")
 }
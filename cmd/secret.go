@@ -0,0 +1,81 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/pbidwell/hippocurl/modules/secret"
+
+	"github.com/spf13/cobra"
+)
+
+// secretCmd represents the secret command
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage the encrypted secret store",
+	Long: `The 'secret' command manages secrets kept in ~/.hc/secrets.json, encrypted with
+AES-GCM using a key derived from a passphrase. The passphrase is asked for, or
+read from $HC_SECRET_PASSPHRASE.
+
+Config values can reference secrets instead of holding them in plaintext; the
+references are resolved when a request is sent:
+
+  ${secret:name}       a secret from this store
+  ${env:VAR}           an environment variable
+  ${file:/path}        the contents of a file, without the trailing newline
+  ${cmd:pass show x}   the output of a shell command
+
+Resolved values are masked in printed headers, curl commands, --output, the
+history and the log file.
+
+Examples:
+  hc secret set github-token
+  hc secret get github-token
+  hc secret list
+  hc secret rm github-token`,
+}
+
+// secretSetCmd represents the secret set command
+var secretSetCmd = &cobra.Command{
+	Use:   "set <name> [value]",
+	Short: "Add or replace a secret, asking for its value if not given",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ExecuteModule(cmd.Context(), secret.SecretModule{}, append([]string{"set"}, args...))
+	},
+}
+
+// secretGetCmd represents the secret get command
+var secretGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Print the value of a secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ExecuteModule(cmd.Context(), secret.SecretModule{}, append([]string{"get"}, args...))
+	},
+}
+
+// secretListCmd represents the secret list command
+var secretListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the names of the stored secrets",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ExecuteModule(cmd.Context(), secret.SecretModule{}, []string{"list"})
+	},
+}
+
+// secretRmCmd represents the secret rm command
+var secretRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ExecuteModule(cmd.Context(), secret.SecretModule{}, append([]string{"rm"}, args...))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretSetCmd, secretGetCmd, secretListCmd, secretRmCmd)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/pbidwell/hippocurl/internal/vars"
+	"github.com/pbidwell/hippocurl/modules/api"
+	"github.com/pbidwell/hippocurl/modules/test"
+
+	"github.com/spf13/cobra"
+)
+
+// testCmd represents the test command
+var testCmd = &cobra.Command{
+	Use:   "test <service_name> <env_name>",
+	Short: "Run a service's routes as an API test suite",
+	Long: `The 'test' command sends every route of a service to the given environment,
+in the order they are configured, and checks each response against the route's
+'assert' block. Routes without assertions pass when they return a 2xx or 3xx status.
+
+Results are printed as a table and the command exits with a non-zero status
+when any route fails, so it can gate CI pipelines.
+
+Examples:
+  hc test ServiceOne staging
+  hc test ServiceOne staging --junit report.xml`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assignments, _ := cmd.Flags().GetStringArray("var")
+		variables, err := vars.ParseAssignments(assignments)
+		if err != nil {
+			return err
+		}
+		junitPath, _ := cmd.Flags().GetString("junit")
+		policy, err := policyFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		ExecuteModule(cmd.Context(), test.TestModule{JUnitPath: junitPath, Overrides: api.Overrides{Variables: variables, Policy: policy}}, args)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+	addPolicyFlags(testCmd)
+	testCmd.Flags().String("junit", "", "Write a JUnit XML report to this file")
+	testCmd.Flags().StringArray("var", nil, "Set a template variable (key=value), can be repeated")
+}
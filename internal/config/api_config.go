@@ -3,36 +3,101 @@ Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
 */
 package config
 
+import "time"
+
 type APIConfig struct {
-	Services []Service `mapstructure:"services"`
+	Policy   `mapstructure:",squash" yaml:",inline"` // Defaults for every service
+	History  History                                 `mapstructure:"history" yaml:"history,omitempty"`
+	Include  []string                                `mapstructure:"include" yaml:"include,omitempty"` // Files whose services are merged in, may be globs
+	Services []Service                               `mapstructure:"services" yaml:"services,omitempty"`
+}
+
+// History configures the request history recorded by "hc api"
+type History struct {
+	Disabled    bool `mapstructure:"disabled" yaml:"disabled,omitempty"`
+	MaxBodySize int  `mapstructure:"max_body_size" yaml:"max_body_size,omitempty"` // Bytes of each body kept, default 64 KiB
+	MaxEntries  int  `mapstructure:"max_entries" yaml:"max_entries,omitempty"`     // Oldest entries are dropped beyond this, default 1000
 }
 
 type Service struct {
-	Name         string        `mapstructure:"name"`
-	Environments []Environment `mapstructure:"environments"`
-	Routes       []Route       `mapstructure:"routes"`
+	Name         string            `mapstructure:"name" yaml:"name"`
+	Variables    map[string]string `mapstructure:"variables,omitempty" yaml:"variables,omitempty"` // Template variables shared by all environments
+	Environments []Environment     `mapstructure:"environments" yaml:"environments,omitempty"`
+	Routes       []Route           `mapstructure:"routes" yaml:"routes,omitempty"`
+	Policy       `mapstructure:",squash" yaml:",inline"`
+
+	File string `mapstructure:"-" yaml:"-"` // Config file the service was read from
 }
 
 type Environment struct {
-	Name    string            `mapstructure:"name"`
-	BaseURL string            `mapstructure:"base_url"`
-	Auth    Auth              `mapstructure:"auth"`
-	Headers map[string]string `mapstructure:"headers,omitempty"` // Custom headers
+	Name      string              `mapstructure:"name" yaml:"name"`
+	BaseURL   string              `mapstructure:"base_url" yaml:"base_url,omitempty"`
+	Auth      Auth                `mapstructure:"auth" yaml:"auth,omitempty"`
+	Headers   map[string]string   `mapstructure:"headers,omitempty" yaml:"headers,omitempty"`     // Custom headers
+	Query     map[string][]string `mapstructure:"query,omitempty" yaml:"query,omitempty"`         // Query parameters added to every route
+	Variables map[string]string   `mapstructure:"variables,omitempty" yaml:"variables,omitempty"` // Override service variables
+	Policy    `mapstructure:",squash" yaml:",inline"`
 }
 
 type Auth struct {
-	Type     string `mapstructure:"type"` // e.g., "basic", "bearer", "none"
-	Username string `mapstructure:"username,omitempty"`
-	Password string `mapstructure:"password,omitempty"`
-	Token    string `mapstructure:"token,omitempty"`
+	Type     string `mapstructure:"type" yaml:"type,omitempty"` // e.g., "basic", "bearer", "oauth2_client_credentials", "none"
+	Username string `mapstructure:"username,omitempty" yaml:"username,omitempty"`
+	Password string `mapstructure:"password,omitempty" yaml:"password,omitempty"`
+	Token    string `mapstructure:"token,omitempty" yaml:"token,omitempty"`
+
+	// OAuth2 settings, used by the "oauth2_*" auth types
+	TokenURL     string   `mapstructure:"token_url,omitempty" yaml:"token_url,omitempty"`
+	ClientID     string   `mapstructure:"client_id,omitempty" yaml:"client_id,omitempty"`
+	ClientSecret string   `mapstructure:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+	RefreshToken string   `mapstructure:"refresh_token,omitempty" yaml:"refresh_token,omitempty"`
+	Scopes       []string `mapstructure:"scopes,omitempty" yaml:"scopes,omitempty"`
+	Audience     string   `mapstructure:"audience,omitempty" yaml:"audience,omitempty"`
 }
 
 type Route struct {
-	Name        string `mapstructure:"name"`
-	Description string `mapstructure:"description"`
-	Method      string `mapstructure:"method"`
-	Path        string `mapstructure:"path"`
-	Body        string `mapstructure:"body"`
+	Name        string              `mapstructure:"name" yaml:"name"`
+	Description string              `mapstructure:"description" yaml:"description,omitempty"`
+	Method      string              `mapstructure:"method" yaml:"method,omitempty"`
+	Path        string              `mapstructure:"path" yaml:"path,omitempty"`
+	Headers     map[string]string   `mapstructure:"headers,omitempty" yaml:"headers,omitempty"` // Added to, or replacing, the environment headers
+	Query       map[string][]string `mapstructure:"query,omitempty" yaml:"query,omitempty"`     // Single values or lists for repeated keys
+	Auth        *Auth               `mapstructure:"auth,omitempty" yaml:"auth,omitempty"`       // Replaces the environment auth when set
+	Body        string              `mapstructure:"body" yaml:"body,omitempty"`
+	Extract     []Extraction        `mapstructure:"extract,omitempty" yaml:"extract,omitempty"`         // Values to capture from the response
+	Assert      *Assertion          `mapstructure:"assert,omitempty" yaml:"assert,omitempty"`           // Expectations checked by "hc test"
+	Ignore      []string            `mapstructure:"ignore,omitempty" yaml:"ignore,omitempty"`           // JSONPaths of body values skipped when comparing responses
+	Mock        *Mock               `mapstructure:"mock,omitempty" yaml:"mock,omitempty"`               // Response served by "hc mock"
+	ImportHash  string              `mapstructure:"import_hash,omitempty" yaml:"import_hash,omitempty"` // Set by "hc import openapi" to detect hand edits
+	Policy      `mapstructure:",squash" yaml:",inline"`
+}
+
+// Extraction captures a value from a response into the variable store.
+// Exactly one of JSONPath, Header or Regex should be set.
+type Extraction struct {
+	Name     string `mapstructure:"name" yaml:"name"`                               // Variable the value is stored under
+	JSONPath string `mapstructure:"json_path,omitempty" yaml:"json_path,omitempty"` // e.g. "$.session.id", for JSON bodies
+	Header   string `mapstructure:"header,omitempty" yaml:"header,omitempty"`       // Response header name
+	Regex    string `mapstructure:"regex,omitempty" yaml:"regex,omitempty"`         // Applied to the body; first capture group if any
+}
+
+// Assertion describes the response a route is expected to return.
+// Only the checks that are set are evaluated.
+type Assertion struct {
+	Status       string            `mapstructure:"status,omitempty" yaml:"status,omitempty"`               // e.g. "200", "2xx" or "200-204"
+	Headers      map[string]string `mapstructure:"headers,omitempty" yaml:"headers,omitempty"`             // Exact header values
+	HeaderRegex  map[string]string `mapstructure:"header_regex,omitempty" yaml:"header_regex,omitempty"`   // Header values matching a regex
+	JSON         map[string]any    `mapstructure:"json,omitempty" yaml:"json,omitempty"`                   // JSONPath expressions and their expected values
+	BodyContains []string          `mapstructure:"body_contains,omitempty" yaml:"body_contains,omitempty"` // Substrings the body must contain
+	MaxLatency   time.Duration     `mapstructure:"max_latency,omitempty" yaml:"max_latency,omitempty"`     // e.g. "500ms"
+}
+
+// Mock is the canned response "hc mock" serves for a route
+type Mock struct {
+	Status   int               `mapstructure:"status,omitempty" yaml:"status,omitempty"` // Default 200
+	Headers  map[string]string `mapstructure:"headers,omitempty" yaml:"headers,omitempty"`
+	Body     string            `mapstructure:"body,omitempty" yaml:"body,omitempty"`           // May use path parameters, e.g. {{id}}
+	BodyFile string            `mapstructure:"body_file,omitempty" yaml:"body_file,omitempty"` // Read on every request, relative to the config file
+	Latency  time.Duration     `mapstructure:"latency,omitempty" yaml:"latency,omitempty"`     // Delay before responding, e.g. "250ms"
 }
 
 // Allows adherence to the Named interface
@@ -89,3 +154,34 @@ func (s *Service) GetEnvironmentNames() []string {
 func (s *Service) GetEnvironmentByName(name string) *Environment {
 	return getByName(s.Environments, name)
 }
+
+// Policy controls how requests are sent. It can be set at the top level of
+// the config file and on services, environments and routes; each level
+// overrides the fields it sets.
+type Policy struct {
+	Timeout    time.Duration `mapstructure:"timeout,omitempty" yaml:"timeout,omitempty"`         // Per attempt, e.g. "30s"
+	Retries    *int          `mapstructure:"retries,omitempty" yaml:"retries,omitempty"`         // Attempts after the first one
+	RetryOn    []string      `mapstructure:"retry_on,omitempty" yaml:"retry_on,omitempty"`       // Statuses such as "503" or "5xx", and "network" for transport errors
+	Backoff    time.Duration `mapstructure:"backoff,omitempty" yaml:"backoff,omitempty"`         // Delay before the first retry, doubled for each further one
+	MaxBackoff time.Duration `mapstructure:"max_backoff,omitempty" yaml:"max_backoff,omitempty"` // Upper bound of the delay between retries
+}
+
+// Override returns p with the fields set in o replacing its own
+func (p Policy) Override(o Policy) Policy {
+	if o.Timeout > 0 {
+		p.Timeout = o.Timeout
+	}
+	if o.Retries != nil {
+		p.Retries = o.Retries
+	}
+	if len(o.RetryOn) > 0 {
+		p.RetryOn = o.RetryOn
+	}
+	if o.Backoff > 0 {
+		p.Backoff = o.Backoff
+	}
+	if o.MaxBackoff > 0 {
+		p.MaxBackoff = o.MaxBackoff
+	}
+	return p
+}
@@ -4,9 +4,14 @@ Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/vars"
 )
 
 var (
@@ -19,11 +24,21 @@ func TestMain(m *testing.M) {
 	base := "testdata"
 
 	// Load configs once
-	minimalConfig = loadAPIConfig(filepath.Join(base, "minimal_config.yml"))
-
-	normalConfig = loadAPIConfig(filepath.Join(base, "normal_config.yml"))
-
-	emptyConfig = loadAPIConfig(filepath.Join(base, "empty_config.yml"))
+	for _, fixture := range []struct {
+		cfg  **APIConfig
+		file string
+	}{
+		{&minimalConfig, "minimal_config.yml"},
+		{&normalConfig, "normal_config.yml"},
+		{&emptyConfig, "empty_config.yml"},
+	} {
+		cfg, err := readAPIConfig(filepath.Join(base, fixture.file), "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "reading %s: %v\n", fixture.file, err)
+			os.Exit(1)
+		}
+		*fixture.cfg = cfg
+	}
 
 	// Run tests
 	os.Exit(m.Run())
@@ -116,3 +131,62 @@ func TestGetEnvironmentByName(t *testing.T) {
 		t.Errorf("expected to find environment NameOne")
 	}
 }
+
+func TestQueryDecoding(t *testing.T) {
+	service := normalConfig.GetServiceByName("ServiceOne")
+	if service == nil {
+		t.Fatal("ServiceOne not found in normalConfig")
+	}
+
+	env := service.GetEnvironmentByName("EnvOneA")
+	if got := env.Query["api-version"]; len(got) != 1 || got[0] != "2" {
+		t.Errorf("expected scalar query value to decode as [2], got %v", got)
+	}
+
+	route := service.GetRouteByName("RouteOneA")
+	if got := route.Query["tag"]; len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected repeated query values [a b], got %v", got)
+	}
+	if got := route.Query["filter"]; len(got) != 1 || got[0] != "x,y" {
+		t.Errorf("expected comma separated value to be kept intact, got %v", got)
+	}
+}
+
+func TestKeyCasePreserved(t *testing.T) {
+	env := normalConfig.GetServiceByName("ServiceOne").GetEnvironmentByName("EnvOneA")
+	if got := env.Variables["userId"]; got != "42" {
+		t.Errorf("expected variable userId to keep its case, got %v", env.Variables)
+	}
+	if got := env.Headers["X-Header-A"]; got != "ValueA" {
+		t.Errorf("expected header X-Header-A to keep its case, got %v", env.Headers)
+	}
+}
+
+func TestCamelCaseVariableResolves(t *testing.T) {
+	env := normalConfig.GetServiceByName("ServiceOne").GetEnvironmentByName("EnvOneA")
+	if got := vars.NewResolver(env.Variables).Expander().Expand("/users/{{userId}}"); got != "/users/42" {
+		t.Errorf("expected {{userId}} to resolve from the config, got %q", got)
+	}
+}
+
+func TestPolicyDecoding(t *testing.T) {
+	service := normalConfig.GetServiceByName("ServiceOne")
+	env := service.GetEnvironmentByName("EnvOneA")
+	route := service.GetRouteByName("RouteOneA")
+
+	policy := normalConfig.Policy.Override(service.Policy).Override(env.Policy).Override(route.Policy)
+	if policy.Timeout != 45*time.Second || policy.Backoff != time.Second {
+		t.Errorf("unexpected durations: %+v", policy)
+	}
+	if policy.Retries == nil || *policy.Retries != 0 {
+		t.Errorf("expected the route's explicit zero retries to win, got %v", policy.Retries)
+	}
+	if !reflect.DeepEqual(policy.RetryOn, []string{"network", "5xx"}) {
+		t.Errorf("unexpected retry_on: %v", policy.RetryOn)
+	}
+
+	other := normalConfig.Policy.Override(service.GetRouteByName("RouteOneB").Policy)
+	if other.Timeout != 10*time.Second || other.Retries == nil || *other.Retries != 1 {
+		t.Errorf("expected the global policy, got %+v", other)
+	}
+}
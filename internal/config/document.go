@@ -0,0 +1,332 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is an API config file loaded as a YAML node tree, so that it can
+// be edited and written back while keeping its comments and formatting
+type Document struct {
+	Path string
+	root *yaml.Node
+}
+
+// LoadDocument reads the API config file at path for editing. A missing or
+// empty file yields an empty document.
+func LoadDocument(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{newMapping()}}
+	}
+	if root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing %s: top level is not a mapping", path)
+	}
+
+	return &Document{Path: path, root: &root}, nil
+}
+
+// Save writes the document back to its file
+func (d *Document) Save() error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(d.root); err != nil {
+		return fmt.Errorf("encoding %s: %w", d.Path, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("encoding %s: %w", d.Path, err)
+	}
+
+	if err := os.WriteFile(d.Path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", d.Path, err)
+	}
+	return nil
+}
+
+// HasService reports whether the document defines a service
+func (d *Document) HasService(name string) bool {
+	return findNamed(d.services(), name) != nil
+}
+
+// AddService appends a service, refusing duplicate names
+func (d *Document) AddService(service Service) error {
+	if d.HasService(service.Name) {
+		return fmt.Errorf("service %s already exists", service.Name)
+	}
+	return appendEncoded(d.services(), service)
+}
+
+// RemoveService deletes a service with its environments and routes
+func (d *Document) RemoveService(name string) error {
+	if !removeNamed(d.services(), name) {
+		return fmt.Errorf("service %s not found", name)
+	}
+	return nil
+}
+
+// RouteNames lists the routes of a service
+func (d *Document) RouteNames(serviceName string) ([]string, error) {
+	return d.names(serviceName, "routes")
+}
+
+// Route returns a service's route as currently defined in the document, or
+// nil if it doesn't exist
+func (d *Document) Route(serviceName, routeName string) (*Route, error) {
+	routes, err := d.serviceList(serviceName, "routes")
+	if err != nil {
+		return nil, err
+	}
+	node := findNamed(routes, routeName)
+	if node == nil {
+		return nil, nil
+	}
+
+	var route Route
+	if err := decodeNode(node, &route); err != nil {
+		return nil, fmt.Errorf("decoding route %s: %w", routeName, err)
+	}
+	return &route, nil
+}
+
+// AddRoute appends a route to a service, refusing duplicate names
+func (d *Document) AddRoute(serviceName string, route Route) error {
+	routes, err := d.serviceList(serviceName, "routes")
+	if err != nil {
+		return err
+	}
+	if findNamed(routes, route.Name) != nil {
+		return fmt.Errorf("route %s already exists in service %s", route.Name, serviceName)
+	}
+	return appendEncoded(routes, route)
+}
+
+// SetRoute replaces a service's route in place, or appends it if the
+// service has no route with that name
+func (d *Document) SetRoute(serviceName string, route Route) error {
+	routes, err := d.serviceList(serviceName, "routes")
+	if err != nil {
+		return err
+	}
+	return setEncoded(routes, route.Name, route)
+}
+
+// RemoveRoute deletes a route from a service
+func (d *Document) RemoveRoute(serviceName, routeName string) error {
+	return d.remove(serviceName, "routes", routeName)
+}
+
+// EnvironmentNames lists the environments of a service
+func (d *Document) EnvironmentNames(serviceName string) ([]string, error) {
+	return d.names(serviceName, "environments")
+}
+
+// Environment returns a service's environment as currently defined in the
+// document, or nil if it doesn't exist
+func (d *Document) Environment(serviceName, envName string) (*Environment, error) {
+	envs, err := d.serviceList(serviceName, "environments")
+	if err != nil {
+		return nil, err
+	}
+	node := findNamed(envs, envName)
+	if node == nil {
+		return nil, nil
+	}
+
+	var env Environment
+	if err := decodeNode(node, &env); err != nil {
+		return nil, fmt.Errorf("decoding environment %s: %w", envName, err)
+	}
+	return &env, nil
+}
+
+// AddEnvironment appends an environment to a service, refusing duplicate names
+func (d *Document) AddEnvironment(serviceName string, env Environment) error {
+	envs, err := d.serviceList(serviceName, "environments")
+	if err != nil {
+		return err
+	}
+	if findNamed(envs, env.Name) != nil {
+		return fmt.Errorf("environment %s already exists in service %s", env.Name, serviceName)
+	}
+	return appendEncoded(envs, env)
+}
+
+// SetEnvironment replaces a service's environment in place, or appends it
+// if the service has no environment with that name
+func (d *Document) SetEnvironment(serviceName string, env Environment) error {
+	envs, err := d.serviceList(serviceName, "environments")
+	if err != nil {
+		return err
+	}
+	return setEncoded(envs, env.Name, env)
+}
+
+// RemoveEnvironment deletes an environment from a service
+func (d *Document) RemoveEnvironment(serviceName, envName string) error {
+	return d.remove(serviceName, "environments", envName)
+}
+
+// decodeNode decodes a node the way the loader decodes the config file, so
+// everything the loader accepts can be edited
+func decodeNode(node *yaml.Node, result any) error {
+	var raw any
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	return decodeAPIConfig(raw, result)
+}
+
+// services returns the top-level services sequence, creating it if needed
+func (d *Document) services() *yaml.Node {
+	return sequenceValue(d.root.Content[0], "services")
+}
+
+// serviceList returns the "routes" or "environments" sequence of a service
+func (d *Document) serviceList(serviceName, key string) (*yaml.Node, error) {
+	service := findNamed(d.services(), serviceName)
+	if service == nil {
+		return nil, fmt.Errorf("service %s not found", serviceName)
+	}
+	return sequenceValue(service, key), nil
+}
+
+// names lists the names in a service's "routes" or "environments"
+func (d *Document) names(serviceName, key string) ([]string, error) {
+	list, err := d.serviceList(serviceName, key)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, item := range list.Content {
+		if item.Kind == yaml.MappingNode {
+			if name := mappingValue(item, "name"); name != nil {
+				names = append(names, name.Value)
+			}
+		}
+	}
+	return names, nil
+}
+
+// remove deletes the named item of a service's "routes" or "environments"
+func (d *Document) remove(serviceName, key, name string) error {
+	list, err := d.serviceList(serviceName, key)
+	if err != nil {
+		return err
+	}
+	if !removeNamed(list, name) {
+		return fmt.Errorf("%s %s not found in service %s", strings.TrimSuffix(key, "s"), name, serviceName)
+	}
+	return nil
+}
+
+// mappingValue returns the value node for key in a mapping node
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sequenceValue returns the sequence stored under key in a mapping node,
+// creating it or replacing an empty value. Sequences are switched to block
+// style so that "services: []" grows into a readable list.
+func sequenceValue(mapping *yaml.Node, key string) *yaml.Node {
+	value := mappingValue(mapping, key)
+	if value == nil {
+		value = &yaml.Node{}
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			value,
+		)
+	}
+	if value.Kind != yaml.SequenceNode {
+		*value = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+	value.Style = 0
+	return value
+}
+
+// findNamed returns the item of a sequence whose "name" is name
+func findNamed(seq *yaml.Node, name string) *yaml.Node {
+	for _, item := range seq.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		if value := mappingValue(item, "name"); value != nil && value.Value == name {
+			return item
+		}
+	}
+	return nil
+}
+
+// removeNamed deletes the item of a sequence whose "name" is name,
+// reporting whether there was one
+func removeNamed(seq *yaml.Node, name string) bool {
+	item := findNamed(seq, name)
+	if item == nil {
+		return false
+	}
+	seq.Content = slices.DeleteFunc(seq.Content, func(node *yaml.Node) bool { return node == item })
+	return true
+}
+
+func appendEncoded(seq *yaml.Node, v any) error {
+	node, err := encodeNode(v)
+	if err != nil {
+		return err
+	}
+	seq.Content = append(seq.Content, node)
+	return nil
+}
+
+// setEncoded replaces the named item of a sequence, keeping the comments
+// attached to it, or appends it
+func setEncoded(seq *yaml.Node, name string, v any) error {
+	node, err := encodeNode(v)
+	if err != nil {
+		return err
+	}
+
+	existing := findNamed(seq, name)
+	if existing == nil {
+		seq.Content = append(seq.Content, node)
+		return nil
+	}
+	node.HeadComment = existing.HeadComment
+	node.LineComment = existing.LineComment
+	node.FootComment = existing.FootComment
+	*existing = *node
+	return nil
+}
+
+func encodeNode(v any) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	return &node, nil
+}
+
+func newMapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
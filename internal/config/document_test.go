@@ -0,0 +1,201 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const commentedConfig = `# Team services
+services:
+  - name: ServiceOne # the first one
+    environments:
+      - name: EnvOne
+        base_url: https://one.example.com
+    routes:
+      # Fetches a user
+      - name: GetUser
+        method: GET
+        path: /users/1
+        body: '{"quoted": "style"}'
+`
+
+func writeTempConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "api_config.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func readTestConfig(t *testing.T, path string) *APIConfig {
+	t.Helper()
+	cfg, err := readAPIConfig(path, "")
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	return cfg
+}
+
+func TestDocumentAddRouteKeepsComments(t *testing.T) {
+	path := writeTempConfig(t, commentedConfig)
+
+	doc, err := LoadDocument(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	route := Route{Name: "ListUsers", Method: "GET", Path: "/users", Query: map[string][]string{"page": {"1"}}}
+	if err := doc.AddRoute("ServiceOne", route); err != nil {
+		t.Fatalf("unexpected error adding route: %v", err)
+	}
+	if err := doc.AddRoute("ServiceOne", route); err == nil {
+		t.Errorf("expected duplicate route to be refused")
+	}
+	if err := doc.AddEnvironment("ServiceOne", Environment{Name: "EnvOne"}); err == nil {
+		t.Errorf("expected duplicate environment to be refused")
+	}
+	if err := doc.AddRoute("Missing", route); err == nil {
+		t.Errorf("expected error for unknown service")
+	}
+	if err := doc.Save(); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{"# Team services", "# the first one", "# Fetches a user", `'{"quoted": "style"}'`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected saved config to keep %q:\n%s", want, data)
+		}
+	}
+
+	cfg := readTestConfig(t, path)
+	added := cfg.GetServiceByName("ServiceOne").GetRouteByName("ListUsers")
+	if added == nil || added.Path != "/users" || added.Query["page"][0] != "1" {
+		t.Errorf("added route not loaded back correctly: %+v", added)
+	}
+}
+
+func TestDocumentSetRoute(t *testing.T) {
+	path := writeTempConfig(t, commentedConfig)
+
+	doc, _ := LoadDocument(path)
+	if err := doc.SetRoute("ServiceOne", Route{Name: "GetUser", Method: "GET", Path: "/users/2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := doc.Save(); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# Fetches a user") {
+		t.Errorf("expected replaced route to keep its comment:\n%s", data)
+	}
+	cfg := readTestConfig(t, path)
+	service := cfg.GetServiceByName("ServiceOne")
+	if len(service.Routes) != 1 || service.Routes[0].Path != "/users/2" {
+		t.Errorf("expected route to be replaced in place, got %+v", service.Routes)
+	}
+}
+
+func TestDocumentDecodesLikeTheLoader(t *testing.T) {
+	path := writeTempConfig(t, `services:
+  - name: ServiceOne
+    environments:
+      - name: EnvOne
+        base_url: https://one.example.com
+        query: {api-version: 2}
+        timeout: 30s
+    routes:
+      - name: ListUsers
+        path: /users
+        query: {page: 1, tag: [a, b]}
+`)
+
+	doc, _ := LoadDocument(path)
+	route, err := doc.Route("ServiceOne", "ListUsers")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := route.Query["page"]; len(got) != 1 || got[0] != "1" {
+		t.Errorf("expected scalar query value to decode as [1], got %v", route.Query)
+	}
+	env, err := doc.Environment("ServiceOne", "EnvOne")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := env.Query["api-version"]; len(got) != 1 || got[0] != "2" || env.Timeout != 30*time.Second {
+		t.Errorf("unexpected query %v or timeout %v", env.Query, env.Timeout)
+	}
+}
+
+func TestDocumentAddServiceToEmptyConfig(t *testing.T) {
+	for _, content := range []string{"", "services: []\n"} {
+		path := writeTempConfig(t, content)
+
+		doc, err := LoadDocument(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := doc.AddService(Service{Name: "New"}); err != nil {
+			t.Fatalf("unexpected error adding service: %v", err)
+		}
+		if err := doc.AddEnvironment("New", Environment{Name: "default", BaseURL: "https://new.example.com"}); err != nil {
+			t.Fatalf("unexpected error adding environment: %v", err)
+		}
+		if err := doc.Save(); err != nil {
+			t.Fatalf("unexpected error saving: %v", err)
+		}
+
+		cfg := readTestConfig(t, path)
+		service := cfg.GetServiceByName("New")
+		if service == nil || service.GetEnvironmentByName("default") == nil {
+			t.Errorf("service not loaded back from %q config: %+v", content, cfg)
+		}
+	}
+}
+
+func TestDocumentRemove(t *testing.T) {
+	path := writeTempConfig(t, commentedConfig)
+	doc, err := LoadDocument(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := doc.AddRoute("ServiceOne", Route{Name: "ListUsers", Path: "/users"}); err != nil {
+		t.Fatalf("unexpected error adding route: %v", err)
+	}
+
+	if err := doc.RemoveRoute("ServiceOne", "GetUser"); err != nil {
+		t.Fatalf("unexpected error removing route: %v", err)
+	}
+	if err := doc.RemoveRoute("ServiceOne", "GetUser"); err == nil {
+		t.Errorf("expected error removing a missing route")
+	}
+	if names, _ := doc.RouteNames("ServiceOne"); len(names) != 1 || names[0] != "ListUsers" {
+		t.Errorf("expected only ListUsers to remain, got %v", names)
+	}
+	if err := doc.RemoveEnvironment("ServiceOne", "EnvOne"); err != nil {
+		t.Fatalf("unexpected error removing environment: %v", err)
+	}
+	if names, _ := doc.EnvironmentNames("ServiceOne"); len(names) != 0 {
+		t.Errorf("expected no environments, got %v", names)
+	}
+	if err := doc.RemoveService("ServiceOne"); err != nil {
+		t.Fatalf("unexpected error removing service: %v", err)
+	}
+	if doc.HasService("ServiceOne") {
+		t.Errorf("expected the service to be removed")
+	}
+	if err := doc.Save(); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# Team services") {
+		t.Errorf("expected the file comment to be kept:\n%s", data)
+	}
+}
@@ -6,12 +6,26 @@ package config
 import "log"
 
 type App struct {
-	GlobalConfig *GlobalConfig
-	APIConfig    *APIConfig
-	Logger       *log.Logger
-	LogFilePath  string
+	GlobalConfig    *GlobalConfig
+	APIConfig       *APIConfig
+	APIConfigPath   string // File the API config was loaded from
+	APIConfigSource string // How APIConfigPath was chosen, one of the Source constants
+	APIConfigErr    error  // Why the API config couldn't be read, if it couldn't
+	Logger          *log.Logger
+	LogFilePath     string
+	ConfigDir       string // ~/.hc, where hc keeps its state files
+	ServicesDir     string // ~/.hc/services.d, whose files are merged into the API config
 }
 type GlobalConfig struct {
 	// global hc file configuration
 	FilePath string
 }
+
+// ServiceFile returns the config file defining a service, or the main API
+// config file for services that don't exist yet
+func (app *App) ServiceFile(name string) string {
+	if service := app.APIConfig.GetServiceByName(name); service != nil && service.File != "" {
+		return service.File
+	}
+	return app.APIConfigPath
+}
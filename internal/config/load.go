@@ -4,27 +4,109 @@ Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
-	"github.com/spf13/viper"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+
+	"github.com/go-viper/mapstructure/v2"
+	"gopkg.in/yaml.v3"
 )
 
 const (
 	hcConfigDirName     = ".hc"
 	hcLogFileName       = "hc.log"
 	hcAPIConfigFileName = "api_config.yml"
+	hcServicesDirName   = "services.d"
 )
 
-func Load() *App {
+// ConfigEnvVar names the environment variable selecting the API config file
+const ConfigEnvVar = "HC_CONFIG"
+
+// Sources of the API config, in order of precedence. See App.APIConfigSource.
+const (
+	SourceFlag    = "--configFilePath"
+	SourceEnv     = "$" + ConfigEnvVar
+	SourceProject = "project" // .hc/api_config.yml in the working directory or a parent
+	SourceDefault = "default" // ~/.hc/api_config.yml
+)
+
+// Load sets up the ~/.hc state directory and reads the API config.
+// configFilePath, from --configFilePath, selects the config file if set.
+// When the API config can't be read, APIConfig is empty and APIConfigErr
+// says why.
+func Load(configFilePath string) *App {
 	configDir := createHCDirectory()
 
 	logger, logFilePath := buildLogger(configDir)
-	return &App{
-		Logger:      logger,
-		LogFilePath: logFilePath,
-		APIConfig:   loadAPIConfig(configDir),
+	apiConfigPath, source, err := resolveAPIConfigPath(configFilePath, configDir)
+	app := &App{
+		GlobalConfig:    &GlobalConfig{FilePath: configFilePath},
+		Logger:          logger,
+		LogFilePath:     logFilePath,
+		ConfigDir:       configDir,
+		ServicesDir:     filepath.Join(configDir, hcServicesDirName),
+		APIConfigPath:   apiConfigPath,
+		APIConfigSource: source,
+	}
+	if err != nil {
+		app.APIConfig, app.APIConfigErr = &APIConfig{}, err
+		return app
+	}
+	if app.APIConfig, app.APIConfigErr = readAPIConfig(apiConfigPath, app.ServicesDir); app.APIConfigErr != nil {
+		app.APIConfig = &APIConfig{}
+	}
+	return app
+}
+
+// resolveAPIConfigPath picks the API config file: configFilePath, then
+// $HC_CONFIG, then the nearest .hc/api_config.yml above the working
+// directory, then the one in configDir. It returns the file and its source,
+// which are still set when an explicitly chosen file doesn't exist.
+func resolveAPIConfigPath(configFilePath, configDir string) (string, string, error) {
+	for _, explicit := range []struct{ path, source string }{
+		{configFilePath, SourceFlag},
+		{os.Getenv(ConfigEnvVar), SourceEnv},
+	} {
+		if explicit.path == "" {
+			continue
+		}
+		path, err := filepath.Abs(explicit.path)
+		if err != nil {
+			return explicit.path, explicit.source, err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return path, explicit.source, fmt.Errorf("config file from %s: %w", explicit.source, err)
+		}
+		return path, explicit.source, nil
+	}
+
+	defaultPath := filepath.Join(configDir, hcAPIConfigFileName)
+	if wd, err := os.Getwd(); err == nil {
+		if path := findProjectConfig(wd); path != "" && path != defaultPath {
+			return path, SourceProject, nil
+		}
+	}
+	return defaultPath, SourceDefault, nil
+}
+
+// findProjectConfig returns the .hc/api_config.yml in dir or its nearest
+// parent that has one, or "" if there is none
+func findProjectConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, hcConfigDirName, hcAPIConfigFileName)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
 	}
 }
 
@@ -59,24 +141,135 @@ func buildLogger(configDir string) (*log.Logger, string) {
 	if err != nil {
 		log.Fatalf("Failed to open log file: %v\n", err)
 	}
-	return log.New(logFile, "", log.Ldate|log.Ltime|log.Lshortfile), logFilePath
+	return log.New(secrets.NewMaskingWriter(logFile), "", log.Ldate|log.Ltime|log.Lshortfile), logFilePath
 }
 
-func loadAPIConfig(configDir string) *APIConfig {
-	path := filepath.Join(configDir, hcAPIConfigFileName)
+// readAPIConfig reads the API config at path and merges in the services of
+// the files it includes and of the *.yml files in servicesDir. Settings
+// other than services and includes are only read from path.
+func readAPIConfig(path, servicesDir string) (*APIConfig, error) {
+	m := &configMerger{loaded: make(map[string]bool), origins: make(map[string]string)}
+	if err := m.merge(path); err != nil {
+		return nil, err
+	}
+	if servicesDir != "" {
+		var files []string
+		for _, pattern := range []string{"*.yml", "*.yaml"} {
+			matches, _ := filepath.Glob(filepath.Join(servicesDir, pattern))
+			files = append(files, matches...)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			if err := m.merge(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m.cfg, nil
+}
 
-	v := viper.New()
-	v.SetConfigFile(path)   // path to config file (e.g., "./config.yml")
-	v.SetConfigType("yaml") // optional if file extension is clear
+// configMerger combines config files into one APIConfig
+type configMerger struct {
+	cfg     *APIConfig        // The first file merged, with the services of all files
+	loaded  map[string]bool   // Files already merged, so each is read once
+	origins map[string]string // File defining each service
+}
 
-	if err := v.ReadInConfig(); err != nil {
-		log.Fatalf("read config: %v", err)
+// merge adds the services of the config file at path, then the files it
+// includes. Include paths are relative to the including file and may be
+// globs.
+func (m *configMerger) merge(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if m.loaded[path] {
+		return nil
+	}
+	m.loaded[path] = true
+
+	cfg, err := readAPIConfigFile(path)
+	if err != nil {
+		return err
+	}
+	services := cfg.Services
+	if m.cfg == nil {
+		m.cfg = cfg
+		m.cfg.Services = nil
+	}
+	for _, service := range services {
+		if origin, ok := m.origins[service.Name]; ok {
+			return fmt.Errorf("service %q is defined in both %s and %s", service.Name, origin, path)
+		}
+		m.origins[service.Name] = path
+		service.File = path
+		m.cfg.Services = append(m.cfg.Services, service)
+	}
+
+	for _, include := range cfg.Include {
+		pattern := expandHome(include)
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: include %q: %w", path, include, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(include, "*?[") {
+			return fmt.Errorf("%s: included file %s not found", path, pattern)
+		}
+		for _, match := range matches {
+			if err := m.merge(match); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readAPIConfigFile decodes a single config file
+func readAPIConfigFile(path string) (*APIConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode through a plain map rather than viper, which lowercases every
+	// map key and would break case-sensitive variable names, headers and
+	// JSONPath expressions
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
 
 	var cfg APIConfig
-	if err := v.Unmarshal(&cfg); err != nil {
-		log.Fatalf("unmarshal config: %v", err)
+	if err := decodeAPIConfig(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
+	return &cfg, nil
+}
 
-	return &cfg
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// decodeAPIConfig decodes raw YAML data into result, the API config or one
+// of its parts. Scalars are converted to the field's type where possible,
+// such as a single query value to a list, and durations may be given as "30s".
+func decodeAPIConfig(raw any, result any) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           result,
+		WeaklyTypedInput: true,
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(raw)
 }
@@ -0,0 +1,112 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "repo")
+	nested := filepath.Join(project, "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := findProjectConfig(nested); got != "" {
+		t.Errorf("expected no project config, got %q", got)
+	}
+
+	want := filepath.Join(project, hcConfigDirName, hcAPIConfigFileName)
+	if err := os.MkdirAll(filepath.Dir(want), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(want, []byte("services: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := findProjectConfig(nested); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestResolveAPIConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	flagPath := filepath.Join(dir, "flag.yml")
+	envPath := filepath.Join(dir, "env.yml")
+	for _, path := range []string{flagPath, envPath} {
+		if err := os.WriteFile(path, []byte("services: []\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(ConfigEnvVar, envPath)
+
+	if path, source, err := resolveAPIConfigPath(flagPath, dir); err != nil || path != flagPath || source != SourceFlag {
+		t.Errorf("expected the flag to win, got %q %q %v", path, source, err)
+	}
+	if path, source, err := resolveAPIConfigPath("", dir); err != nil || path != envPath || source != SourceEnv {
+		t.Errorf("expected $%s, got %q %q %v", ConfigEnvVar, path, source, err)
+	}
+	if _, _, err := resolveAPIConfigPath(filepath.Join(dir, "missing.yml"), dir); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
+
+func TestLoadMissingConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	missing := filepath.Join(t.TempDir(), "missing.yml")
+
+	app := Load(missing)
+	if app.APIConfigErr == nil || app.APIConfigPath != missing || app.APIConfigSource != SourceFlag {
+		t.Fatalf("expected the missing file to be reported, got %q %q %v", app.APIConfigPath, app.APIConfigSource, app.APIConfigErr)
+	}
+	problems, err := Validate(app.APIConfigPath, app.ServicesDir)
+	if err != nil || len(problems) != 1 || problems[0].File != missing {
+		t.Errorf("expected the validator to report the missing file, got %v %v", problems, err)
+	}
+}
+
+func TestReadAPIConfigMergesFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"api_config.yml":        "include:\n  - teams/*.yml\nservices:\n  - name: Main\n",
+		"teams/payments.yml":    "services:\n  - name: Payments\n    routes:\n      - name: charge\n",
+		"services.d/search.yml": "services:\n  - name: Search\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := readAPIConfig(filepath.Join(dir, "api_config.yml"), filepath.Join(dir, "services.d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := cfg.GetServiceNames(); len(names) != 3 {
+		t.Fatalf("expected 3 services, got %v", names)
+	}
+	payments := cfg.GetServiceByName("Payments")
+	if payments == nil || payments.GetRouteByName("charge") == nil {
+		t.Fatalf("expected the included Payments service with its route, got %+v", payments)
+	}
+	if payments.File != filepath.Join(dir, "teams", "payments.yml") {
+		t.Errorf("unexpected file for Payments: %q", payments.File)
+	}
+
+	duplicate := filepath.Join(dir, "services.d", "payments.yml")
+	if err := os.WriteFile(duplicate, []byte("services:\n  - name: Payments\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = readAPIConfig(filepath.Join(dir, "api_config.yml"), filepath.Join(dir, "services.d"))
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "teams", "payments.yml")) || !strings.Contains(err.Error(), duplicate) {
+		t.Errorf("expected a duplicate service error citing both files, got %v", err)
+	}
+}
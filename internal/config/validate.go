@@ -0,0 +1,594 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/secrets"
+	"github.com/pbidwell/hippocurl/internal/vars"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is an issue found by Validate
+type Problem struct {
+	File    string
+	Line    int // 0 when the problem concerns the whole file
+	Column  int
+	Message string
+	Warning bool // The config loads, but requests may fail, e.g. on undefined variables
+}
+
+func (p Problem) String() string {
+	switch {
+	case p.Line == 0:
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Column, p.Message)
+}
+
+// AuthTypes lists the auth types supported by "hc api"
+var AuthTypes = []string{"none", "basic", "bearer", "oauth2_client_credentials", "oauth2_refresh_token"}
+
+// requiredAuthFields lists the fields each auth type needs
+var requiredAuthFields = map[string][]string{
+	"basic":                     {"username"},
+	"bearer":                    {"token"},
+	"oauth2_client_credentials": {"token_url", "client_id"},
+	"oauth2_refresh_token":      {"token_url", "refresh_token"},
+}
+
+// expandedAuthFields are the auth fields that may use {{variables}}
+var expandedAuthFields = []string{"username", "password", "token", "token_url", "client_id", "client_secret", "refresh_token", "audience"}
+
+var httpMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true, http.MethodOptions: true, http.MethodTrace: true, http.MethodConnect: true,
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	yamlLine     = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+)
+
+// Validate checks the API config at path, the files it includes and the
+// *.yml files in servicesDir, as Load merges them. Problems are sorted by
+// file and position. A file that can't be read is reported as a problem.
+func Validate(path, servicesDir string) ([]Problem, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{files: make(map[string]int), services: make(map[string]string)}
+	v.validateFile(path)
+	if servicesDir != "" {
+		var files []string
+		for _, pattern := range []string{"*.yml", "*.yaml"} {
+			matches, _ := filepath.Glob(filepath.Join(servicesDir, pattern))
+			files = append(files, matches...)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			v.validateFile(file)
+		}
+	}
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		a, b := v.problems[i], v.problems[j]
+		if a.File != b.File {
+			return v.files[a.File] < v.files[b.File]
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return v.problems, nil
+}
+
+// validator collects the problems of a set of config files
+type validator struct {
+	problems []Problem
+	files    map[string]int    // Order in which each file was checked
+	services map[string]string // Location of each service name, for duplicates
+}
+
+func (v *validator) add(file string, node *yaml.Node, warning bool, format string, args ...any) {
+	problem := Problem{File: file, Message: fmt.Sprintf(format, args...), Warning: warning}
+	if node != nil {
+		problem.Line, problem.Column = node.Line, node.Column
+	}
+	v.problems = append(v.problems, problem)
+}
+
+func (v *validator) errorf(file string, node *yaml.Node, format string, args ...any) {
+	v.add(file, node, false, format, args...)
+}
+
+func (v *validator) warnf(file string, node *yaml.Node, format string, args ...any) {
+	v.add(file, node, true, format, args...)
+}
+
+// validateFile checks a config file, then the files it includes
+func (v *validator) validateFile(path string) {
+	if _, ok := v.files[path]; ok {
+		return
+	}
+	v.files[path] = len(v.files)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		v.errorf(path, nil, "%v", err)
+		return
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		problem := Problem{File: path, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if match := yamlLine.FindStringSubmatch(err.Error()); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+			problem.Message = strings.TrimPrefix(err.Error(), match[0])
+		}
+		v.problems = append(v.problems, problem)
+		return
+	}
+	if len(doc.Content) == 0 {
+		return
+	}
+	root := resolveAlias(doc.Content[0])
+	if isNull(root) {
+		return
+	}
+	if root.Kind != yaml.MappingNode {
+		v.errorf(path, root, "expected a mapping with a services list")
+		return
+	}
+
+	v.checkFields(path, root, reflect.TypeOf(APIConfig{}))
+	for _, service := range items(field(root, "services")) {
+		v.checkService(path, service)
+	}
+
+	for _, include := range items(field(root, "include")) {
+		pattern := expandHome(include.Value)
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			v.errorf(path, include, "invalid include pattern %q: %v", include.Value, err)
+			continue
+		}
+		if len(matches) == 0 && !strings.ContainsAny(include.Value, "*?[") {
+			v.errorf(path, include, "included file %s not found", pattern)
+		}
+		for _, match := range matches {
+			v.validateFile(match)
+		}
+	}
+}
+
+// checkFields reports the keys of node that t has no field for and values
+// of the wrong kind, recursing into nested blocks
+func (v *validator) checkFields(file string, node *yaml.Node, t reflect.Type) {
+	node = resolveAlias(node)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isNull(node) || t.Kind() == reflect.Interface {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			v.errorf(file, node, "expected a mapping")
+			return
+		}
+		fields := structFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldType, ok := fields[strings.ToLower(key.Value)]
+			if !ok {
+				if suggestion := closestKey(key.Value, fields); suggestion != "" {
+					v.errorf(file, key, "unknown key %q, did you mean %q?", key.Value, suggestion)
+				} else {
+					v.errorf(file, key, "unknown key %q", key.Value)
+				}
+				continue
+			}
+			v.checkFields(file, value, fieldType)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.errorf(file, node, "expected a mapping")
+			return
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			v.checkFields(file, node.Content[i], t.Elem())
+		}
+	case reflect.Slice:
+		switch {
+		case node.Kind == yaml.SequenceNode:
+			for _, item := range node.Content {
+				v.checkFields(file, item, t.Elem())
+			}
+		case node.Kind == yaml.ScalarNode && t.Elem().Kind() != reflect.Struct:
+			// A single value is accepted for a list of values
+			v.checkFields(file, node, t.Elem())
+		default:
+			v.errorf(file, node, "expected a list")
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			v.errorf(file, node, "expected a single value")
+			return
+		}
+		v.checkScalar(file, node, t)
+	}
+}
+
+// checkScalar reports values that can't be converted to t
+func (v *validator) checkScalar(file string, node *yaml.Node, t reflect.Type) {
+	var err error
+	switch {
+	case t == durationType:
+		if _, err = time.ParseDuration(node.Value); err != nil {
+			if _, intErr := strconv.ParseInt(node.Value, 10, 64); intErr == nil {
+				err = nil
+			}
+		}
+		if err != nil {
+			v.errorf(file, node, "invalid duration %q, expected a value such as \"30s\"", node.Value)
+		}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		if _, err = strconv.ParseInt(node.Value, 10, 64); err != nil {
+			v.errorf(file, node, "expected a number, got %q", node.Value)
+		}
+	case t.Kind() == reflect.Bool:
+		if _, err = strconv.ParseBool(node.Value); err != nil {
+			v.errorf(file, node, "expected true or false, got %q", node.Value)
+		}
+	}
+}
+
+// checkService checks the names, URLs, methods, auth and variables of a
+// service
+func (v *validator) checkService(file string, service *yaml.Node) {
+	service = resolveAlias(service)
+	if service.Kind != yaml.MappingNode {
+		return
+	}
+	name := v.checkName(file, service, "service")
+	if name != nil {
+		if first, ok := v.services[name.Value]; ok {
+			v.errorf(file, name, "service %q is already defined at %s", name.Value, first)
+		} else {
+			v.services[name.Value] = fmt.Sprintf("%s:%d:%d", file, name.Line, name.Column)
+		}
+	}
+
+	envs := items(field(service, "environments"))
+	routes := items(field(service, "routes"))
+	v.checkUnique(file, envs, "environment")
+	v.checkUnique(file, routes, "route")
+
+	// Variables defined for every environment
+	shared := keys(field(service, "variables"))
+	for _, route := range routes {
+		for _, extraction := range items(field(route, "extract")) {
+			if name := field(extraction, "name"); name != nil {
+				shared[name.Value] = true
+			}
+		}
+	}
+
+	defined := make([]map[string]bool, len(envs))
+	for i, env := range envs {
+		v.checkName(file, env, "environment")
+		envName := scalar(field(env, "name"))
+		if baseURL := field(env, "base_url"); scalar(baseURL) == "" {
+			v.errorf(file, env, "environment %s is missing base_url", envName)
+		} else {
+			v.checkURL(file, baseURL, "base_url")
+		}
+		v.checkAuth(file, field(env, "auth"))
+
+		defined[i] = keys(field(env, "variables"))
+		for name := range shared {
+			defined[i][name] = true
+		}
+		v.checkVariables(file, env, envTemplates, envs[i:i+1], defined[i:i+1])
+	}
+	if len(envs) == 0 && len(routes) > 0 {
+		v.warnf(file, service, "service %s has no environments to send its routes to", scalar(name))
+	}
+
+	for _, route := range routes {
+		v.checkName(file, route, "route")
+		if method := field(route, "method"); scalar(method) != "" && !httpMethods[strings.ToUpper(method.Value)] {
+			v.errorf(file, method, "invalid HTTP method %q", method.Value)
+		}
+		v.checkAuth(file, field(route, "auth"))
+		for _, extraction := range items(field(route, "extract")) {
+			v.checkName(file, extraction, "extract entry")
+			sources := 0
+			for _, key := range []string{"json_path", "header", "regex"} {
+				if scalar(field(extraction, key)) != "" {
+					sources++
+				}
+			}
+			if sources != 1 {
+				v.errorf(file, extraction, "extract entry needs exactly one of json_path, header or regex")
+			}
+		}
+		if len(envs) > 0 {
+			v.checkVariables(file, route, routeTemplates, envs, defined)
+		}
+	}
+}
+
+// checkName reports a missing name, returning the name node otherwise
+func (v *validator) checkName(file string, node *yaml.Node, kind string) *yaml.Node {
+	name := field(node, "name")
+	if scalar(name) == "" {
+		v.errorf(file, node, "%s is missing a name", kind)
+		return nil
+	}
+	return name
+}
+
+// checkUnique reports items sharing a name
+func (v *validator) checkUnique(file string, list []*yaml.Node, kind string) {
+	seen := make(map[string]*yaml.Node)
+	for _, item := range list {
+		name := field(item, "name")
+		if scalar(name) == "" {
+			continue
+		}
+		if first, ok := seen[name.Value]; ok {
+			v.errorf(file, name, "duplicate %s %q, first defined on line %d", kind, name.Value, first.Line)
+			continue
+		}
+		seen[name.Value] = name
+	}
+}
+
+// checkURL reports values that aren't valid base or token URLs
+func (v *validator) checkURL(file string, node *yaml.Node, key string) {
+	if err := CheckURL(node.Value); err != nil {
+		v.errorf(file, node, "invalid %s %q: %v", key, node.Value, err)
+	}
+}
+
+// CheckURL reports whether value is an absolute http or https URL. URLs
+// starting with a variable or secret reference can't be checked and are
+// accepted.
+func CheckURL(value string) error {
+	if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "{{") || strings.HasPrefix(trimmed, "${") {
+		return nil
+	}
+	u, err := url.Parse(secrets.Reference.ReplaceAllString(vars.Placeholder.ReplaceAllString(value, "x"), "x"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("expected an http:// or https:// URL")
+	}
+	return nil
+}
+
+// checkAuth reports unknown auth types and missing fields
+func (v *validator) checkAuth(file string, auth *yaml.Node) {
+	auth = resolveAlias(auth)
+	if auth == nil || auth.Kind != yaml.MappingNode {
+		return
+	}
+	authType := field(auth, "type")
+	kind := strings.ToLower(strings.TrimSpace(scalar(authType)))
+	if kind != "" && kind != "none" {
+		if _, ok := requiredAuthFields[kind]; !ok {
+			v.errorf(file, authType, "unknown auth type %q, expected one of %s", authType.Value, strings.Join(AuthTypes, ", "))
+			return
+		}
+	}
+	for _, key := range requiredAuthFields[kind] {
+		if scalar(field(auth, key)) == "" {
+			v.errorf(file, auth, "%s auth requires %s", kind, key)
+		}
+	}
+	if tokenURL := field(auth, "token_url"); scalar(tokenURL) != "" {
+		v.checkURL(file, tokenURL, "token_url")
+	}
+}
+
+// Fields of environments and routes expanded with {{variables}}
+var (
+	envTemplates   = []string{"base_url", "headers", "query"}
+	routeTemplates = []string{"path", "headers", "query", "body"}
+)
+
+// checkVariables warns about placeholders in the templated fields of node
+// that aren't defined for some of envs. defined holds the variables of
+// each environment. Variables can still be passed with --var or extracted
+// by earlier requests, so these are warnings.
+func (v *validator) checkVariables(file string, node *yaml.Node, templated []string, envs []*yaml.Node, defined []map[string]bool) {
+	var values []*yaml.Node
+	for _, key := range templated {
+		values = append(values, scalars(field(node, key))...)
+	}
+	if auth := field(node, "auth"); auth != nil {
+		for _, key := range expandedAuthFields {
+			values = append(values, scalars(field(auth, key))...)
+		}
+	}
+
+	for _, value := range values {
+		reported := make(map[string]bool)
+		for _, match := range vars.Placeholder.FindAllStringSubmatch(value.Value, -1) {
+			name := match[1]
+			if reported[name] {
+				continue
+			}
+			reported[name] = true
+			if envName, ok := strings.CutPrefix(name, "env."); ok {
+				if _, set := os.LookupEnv(envName); !set {
+					v.warnf(file, value, "environment variable %s used by {{%s}} is not set", envName, name)
+				}
+				continue
+			}
+			var missing []string
+			for i, env := range envs {
+				if envName := scalar(field(env, "name")); !defined[i][name] && !slices.Contains(missing, envName) {
+					missing = append(missing, envName)
+				}
+			}
+			if len(missing) > 0 {
+				v.warnf(file, value, "variable %q is not defined for environment %s", name, strings.Join(missing, ", "))
+			}
+		}
+	}
+}
+
+// structFields maps the lowercased keys decoded into a struct to their
+// types, including those of squashed structs
+func structFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		switch {
+		case name == "-":
+			continue
+		case strings.Contains(options, "squash"):
+			for key, fieldType := range structFields(f.Type) {
+				fields[key] = fieldType
+			}
+			continue
+		case name == "":
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
+
+// closestKey returns the known key nearest to key, if it is close enough to
+// be a typo
+func closestKey(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	for name := range fields {
+		if d := editDistance(strings.ToLower(key), name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if bestDistance > 2 {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// field returns the value of key in a mapping node, or nil
+func field(node *yaml.Node, key string) *yaml.Node {
+	node = resolveAlias(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	return resolveAlias(mappingValue(node, key))
+}
+
+// items returns the mapping items of a sequence node
+func items(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var list []*yaml.Node
+	for _, item := range node.Content {
+		if item = resolveAlias(item); item.Kind == yaml.MappingNode || item.Kind == yaml.ScalarNode {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// keys returns the keys of a mapping node
+func keys(node *yaml.Node) map[string]bool {
+	set := make(map[string]bool)
+	if node != nil && node.Kind == yaml.MappingNode {
+		for i := 0; i < len(node.Content); i += 2 {
+			set[node.Content[i].Value] = true
+		}
+	}
+	return set
+}
+
+// scalars returns every scalar below node, including mapping keys
+func scalars(node *yaml.Node) []*yaml.Node {
+	node = resolveAlias(node)
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		return []*yaml.Node{node}
+	}
+	var list []*yaml.Node
+	for _, child := range node.Content {
+		list = append(list, scalars(child)...)
+	}
+	return list
+}
+
+// scalar returns the value of a scalar node, or ""
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode || isNull(node) {
+		return ""
+	}
+	return node.Value
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	path := writeTempConfig(t, `retries: many
+include:
+  - missing.yml
+services:
+  - name: Users
+    environments:
+      - name: dev
+        base_url: "ftp://example.com"
+        auth:
+          type: oauth2_client_credentials
+          token_url: https://auth.example.com/token
+      - name: dev
+        base_url: "https://{{host}}"
+    routes:
+      - name: get-user
+        methd: GET
+        path: /users/{{id}}
+      - name: get-user
+        method: GIT
+      - path: /nameless
+`)
+	problems, err := Validate(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`:1:10: expected a number, got "many"`,
+		`:3:5: included file`,
+		`:8:19: invalid base_url "ftp://example.com"`,
+		`:10:11: oauth2_client_credentials auth requires client_id`,
+		`:12:15: duplicate environment "dev", first defined on line 7`,
+		`:13:19: variable "host" is not defined for environment dev`,
+		`:16:9: unknown key "methd", did you mean "method"?`,
+		`:17:15: variable "id" is not defined for environment dev`,
+		`:18:15: duplicate route "get-user", first defined on line 15`,
+		`:19:17: invalid HTTP method "GIT"`,
+		`:20:9: route is missing a name`,
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %d: %v", len(want), len(problems), problems)
+	}
+	for i, problem := range problems {
+		if !strings.Contains(problem.String(), want[i]) {
+			t.Errorf("problem %d: expected %q in %q", i, want[i], problem.String())
+		}
+	}
+	if !problems[5].Warning || problems[6].Warning {
+		t.Error("expected only undefined variables to be warnings")
+	}
+}
+
+func TestValidateSyntaxError(t *testing.T) {
+	path := writeTempConfig(t, "services:\n  - name: a\n    path: [/a\n")
+	problems, err := Validate(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].Line == 0 || strings.HasPrefix(problems[0].Message, "yaml:") {
+		t.Errorf("expected a syntax error with its line, got %v", problems)
+	}
+}
+
+func TestValidateSample(t *testing.T) {
+	path := writeTempConfig(t, APIConfigSampleYaml)
+	problems, err := Validate(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, problem := range problems {
+		if !problem.Warning {
+			t.Errorf("unexpected error in the sample config: %s", problem)
+		}
+	}
+}
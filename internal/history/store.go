@@ -0,0 +1,246 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+// FileName is the file, inside the hc config directory, that holds the
+// request history
+const FileName = "history.jsonl"
+
+const (
+	defaultMaxBodySize = 64 * 1024
+	defaultMaxEntries  = 1000
+)
+
+// Entry is one recorded API call
+type Entry struct {
+	ID          int                 `json:"id"`
+	Time        time.Time           `json:"time"`
+	Service     string              `json:"service"`
+	Route       string              `json:"route"`
+	Environment string              `json:"environment"`
+	Variables   map[string]string   `json:"variables,omitempty"` // --var overrides, for re-running the entry
+	Query       map[string][]string `json:"query,omitempty"`     // --query overrides
+	Request     Request             `json:"request"`
+	Response    *Response           `json:"response,omitempty"`
+	Timings     *Timings            `json:"timings,omitempty"`
+	Error       string              `json:"error,omitempty"` // Set when no response was received
+}
+
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    Body        `json:"body"`
+}
+
+type Response struct {
+	Status     string      `json:"status"`
+	StatusCode int         `json:"status_code"`
+	Proto      string      `json:"proto"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       Body        `json:"body"`
+}
+
+// Body is a stored request or response body
+type Body struct {
+	Text      string `json:"text,omitempty"`
+	Size      int    `json:"size"`                // Size of the original body in bytes
+	Truncated bool   `json:"truncated,omitempty"` // Text holds only the start of the body
+	Binary    bool   `json:"binary,omitempty"`    // The body wasn't UTF-8 text and isn't stored
+}
+
+// Timings are the phases of a request in milliseconds
+type Timings struct {
+	DNSMS      float64 `json:"dns_ms"`
+	ConnectMS  float64 `json:"connect_ms"`
+	TLSMS      float64 `json:"tls_ms"`
+	WaitMS     float64 `json:"wait_ms"`
+	TransferMS float64 `json:"transfer_ms"`
+	TotalMS    float64 `json:"total_ms"`
+	Reused     bool    `json:"reused"`
+	Protocol   string  `json:"protocol,omitempty"`
+	TLSVersion string  `json:"tls_version,omitempty"`
+}
+
+// NewBody stores body, keeping at most maxSize bytes of it
+func NewBody(body []byte, maxSize int) Body {
+	stored := Body{Size: len(body)}
+	if !utf8.Valid(body) {
+		stored.Binary = true
+		return stored
+	}
+	if len(body) > maxSize {
+		// Cut on a rune boundary so the stored text stays valid UTF-8
+		for maxSize > 0 && !utf8.RuneStart(body[maxSize]) {
+			maxSize--
+		}
+		body, stored.Truncated = body[:maxSize], true
+	}
+	stored.Text = string(body)
+	return stored
+}
+
+// Store is the request history, kept as one JSON document per line
+type Store struct {
+	path        string
+	Disabled    bool
+	MaxBodySize int
+	MaxEntries  int
+}
+
+// NewStore returns the history at path with the given settings, using the
+// defaults for unset limits
+func NewStore(path string, settings config.History) *Store {
+	store := &Store{
+		path:        path,
+		Disabled:    settings.Disabled,
+		MaxBodySize: settings.MaxBodySize,
+		MaxEntries:  settings.MaxEntries,
+	}
+	if store.MaxBodySize <= 0 {
+		store.MaxBodySize = defaultMaxBodySize
+	}
+	if store.MaxEntries <= 0 {
+		store.MaxEntries = defaultMaxEntries
+	}
+	return store
+}
+
+// Entries returns every entry, oldest first. A missing file yields none.
+func (s *Store) Entries() ([]Entry, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("parsing history %s line %d: %w", s.path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	return entries, nil
+}
+
+// Get returns the entry with the given id, or nil if there is none
+func (s *Store) Get(id int) (*Entry, error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Append numbers entry and adds it to the history, dropping the oldest
+// entries beyond MaxEntries. Bodies are truncated to MaxBodySize.
+func (s *Store) Append(entry *Entry) error {
+	if s.Disabled {
+		return nil
+	}
+	entries, err := s.Entries()
+	if err != nil {
+		return err
+	}
+
+	entry.ID = 1
+	if len(entries) > 0 {
+		entry.ID = entries[len(entries)-1].ID + 1
+	}
+	s.truncate(&entry.Request.Body)
+	if entry.Response != nil {
+		s.truncate(&entry.Response.Body)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if len(entries) < s.MaxEntries {
+		file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("writing history: %w", err)
+		}
+		defer file.Close()
+		if _, err := file.Write(line); err != nil {
+			return fmt.Errorf("writing history: %w", err)
+		}
+		return nil
+	}
+	return s.rewrite(append(entries[len(entries)-s.MaxEntries+1:], *entry))
+}
+
+// truncate applies MaxBodySize to a body that may have been stored whole
+func (s *Store) truncate(body *Body) {
+	if len(body.Text) > s.MaxBodySize {
+		size := body.Size
+		*body = NewBody([]byte(body.Text), s.MaxBodySize)
+		body.Size = size
+	}
+}
+
+// rewrite replaces the history with entries
+func (s *Store) rewrite(entries []Entry) error {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".history-*")
+	if err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package history
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+func TestStoreAppend(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), FileName), config.History{MaxBodySize: 10, MaxEntries: 3})
+
+	for _, route := range []string{"a", "b", "c", "d"} {
+		entry := &Entry{
+			Route:    route,
+			Response: &Response{StatusCode: 200, Body: NewBody([]byte(strings.Repeat(route, 20)), 1024)},
+		}
+		if err := store.Append(entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 3 || entries[0].ID != 2 || entries[2].ID != 4 || entries[2].Route != "d" {
+		t.Fatalf("expected entries 2-4 to be kept, got %+v", entries)
+	}
+
+	body := entries[2].Response.Body
+	if body.Text != strings.Repeat("d", 10) || !body.Truncated || body.Size != 20 {
+		t.Errorf("expected the body to be truncated to 10 of 20 bytes, got %+v", body)
+	}
+
+	entry, err := store.Get(3)
+	if err != nil || entry == nil || entry.Route != "c" {
+		t.Errorf("expected entry 3 to be route c, got %+v, %v", entry, err)
+	}
+	if entry, _ := store.Get(1); entry != nil {
+		t.Errorf("expected entry 1 to have been dropped, got %+v", entry)
+	}
+}
+
+func TestNewBody(t *testing.T) {
+	if body := NewBody([]byte("héllo"), 2); body.Text != "h" || !body.Truncated || body.Size != 6 {
+		t.Errorf("expected truncation on a rune boundary, got %+v", body)
+	}
+	if body := NewBody([]byte{0xff, 0xfe}, 10); !body.Binary || body.Text != "" {
+		t.Errorf("expected a binary body, got %+v", body)
+	}
+}
+
+func TestStoreDisabled(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), FileName), config.History{Disabled: true})
+	if err := store.Append(&Entry{Route: "a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries, _ := store.Entries(); len(entries) != 0 {
+		t.Errorf("expected nothing to be recorded, got %+v", entries)
+	}
+}
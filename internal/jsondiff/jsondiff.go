@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+
+// Package jsondiff compares decoded JSON documents structurally, reporting
+// each value that differs by its path.
+package jsondiff
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/pbidwell/hippocurl/internal/jsonpath"
+)
+
+// Kinds of difference
+const (
+	Changed = "changed" // The value differs
+	Added   = "added"   // The value is only in the second document
+	Removed = "removed" // The value is only in the first document
+)
+
+// Difference is one value that differs between two documents
+type Difference struct {
+	Path  string `json:"path"` // JSONPath of the value, "$" for the root
+	Kind  string `json:"kind"`
+	Left  any    `json:"left,omitempty"`
+	Right any    `json:"right,omitempty"`
+}
+
+// Result lists the differences found by Compare
+type Result struct {
+	Differences []Difference
+	Ignored     int // Differences matched by an ignore path
+}
+
+// Compare returns the differences between left and right, decoded with
+// jsonpath.Decode. Differences at or below an ignore path are only counted.
+// Arrays are compared by index.
+func Compare(left, right any, ignore []*jsonpath.Path) Result {
+	c := &comparer{ignore: ignore}
+	c.compare(nil, left, right)
+	return c.result
+}
+
+type comparer struct {
+	ignore []*jsonpath.Path
+	result Result
+}
+
+func (c *comparer) compare(location []any, left, right any) {
+	for _, path := range c.ignore {
+		if path.Contains(location) {
+			if !reflect.DeepEqual(left, right) {
+				c.result.Ignored++
+			}
+			return
+		}
+	}
+
+	switch l := left.(type) {
+	case map[string]any:
+		if r, ok := right.(map[string]any); ok {
+			c.compareObjects(location, l, r)
+			return
+		}
+	case []any:
+		if r, ok := right.([]any); ok {
+			c.compareArrays(location, l, r)
+			return
+		}
+	}
+	if !reflect.DeepEqual(left, right) {
+		c.add(location, Changed, left, right)
+	}
+}
+
+func (c *comparer) compareObjects(location []any, left, right map[string]any) {
+	keys := make([]string, 0, len(left)+len(right))
+	for key := range left {
+		keys = append(keys, key)
+	}
+	for key := range right {
+		if _, ok := left[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := append(location[:len(location):len(location)], key)
+		l, inLeft := left[key]
+		r, inRight := right[key]
+		switch {
+		case !inRight:
+			c.missing(child, Removed, l, nil)
+		case !inLeft:
+			c.missing(child, Added, nil, r)
+		default:
+			c.compare(child, l, r)
+		}
+	}
+}
+
+func (c *comparer) compareArrays(location []any, left, right []any) {
+	for i := 0; i < max(len(left), len(right)); i++ {
+		child := append(location[:len(location):len(location)], i)
+		switch {
+		case i >= len(right):
+			c.missing(child, Removed, left[i], nil)
+		case i >= len(left):
+			c.missing(child, Added, nil, right[i])
+		default:
+			c.compare(child, left[i], right[i])
+		}
+	}
+}
+
+// missing records a value present in only one document, unless ignored
+func (c *comparer) missing(location []any, kind string, left, right any) {
+	for _, path := range c.ignore {
+		if path.Contains(location) {
+			c.result.Ignored++
+			return
+		}
+	}
+	c.add(location, kind, left, right)
+}
+
+func (c *comparer) add(location []any, kind string, left, right any) {
+	c.result.Differences = append(c.result.Differences, Difference{
+		Path:  jsonpath.FormatLocation(location),
+		Kind:  kind,
+		Left:  left,
+		Right: right,
+	})
+}
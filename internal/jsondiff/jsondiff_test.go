@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package jsondiff
+
+import (
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/jsonpath"
+)
+
+func TestCompare(t *testing.T) {
+	left, _ := jsonpath.Decode([]byte(`{"id":1,"name":"hippo","tags":["a","b"],"meta":{"updated_at":"2025-01-01"},"old":true}`))
+	right, _ := jsonpath.Decode([]byte(`{"id":2,"name":"hippo","tags":["a","c","d"],"meta":{"updated_at":"2025-02-01"},"new":1}`))
+
+	ignore, _ := jsonpath.Parse("$.meta.updated_at")
+	result := Compare(left, right, []*jsonpath.Path{ignore})
+
+	want := []Difference{
+		{Path: "$.id", Kind: Changed, Left: float64(1), Right: float64(2)},
+		{Path: "$.new", Kind: Added, Right: float64(1)},
+		{Path: "$.old", Kind: Removed, Left: true},
+		{Path: "$.tags[1]", Kind: Changed, Left: "b", Right: "c"},
+		{Path: "$.tags[2]", Kind: Added, Right: "d"},
+	}
+	if len(result.Differences) != len(want) {
+		t.Fatalf("expected %d differences, got %+v", len(want), result.Differences)
+	}
+	for i, diff := range result.Differences {
+		if diff != want[i] {
+			t.Errorf("difference %d: expected %+v, got %+v", i, want[i], diff)
+		}
+	}
+	if result.Ignored != 1 {
+		t.Errorf("expected 1 ignored difference, got %d", result.Ignored)
+	}
+}
+
+func TestCompareTypeChange(t *testing.T) {
+	result := Compare(map[string]any{"a": []any{}}, map[string]any{"a": map[string]any{}}, nil)
+	if len(result.Differences) != 1 || result.Differences[0].Path != "$.a" || result.Differences[0].Kind != Changed {
+		t.Errorf("expected $.a to have changed, got %+v", result.Differences)
+	}
+	if result := Compare("same", "same", nil); len(result.Differences) != 0 {
+		t.Errorf("expected no differences, got %+v", result.Differences)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+
+// Package jsonpath implements the subset of JSONPath hc needs to address
+// values in decoded JSON documents: "$", ".key", "['key']", "[index]" and
+// the "*" / "[*]" wildcards.
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is one step of a parsed path
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Path is a parsed JSONPath expression
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Parse compiles a JSONPath expression. The leading "$" is optional.
+func Parse(expr string) (*Path, error) {
+	p := &Path{expr: expr}
+	rest := strings.TrimSpace(expr)
+	rest = strings.TrimPrefix(rest, "$")
+
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid path %q: empty key", expr)
+			}
+			if name == "*" {
+				p.segments = append(p.segments, segment{wildcard: true})
+			} else {
+				p.segments = append(p.segments, segment{key: name})
+			}
+			rest = rest[end:]
+
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("invalid path %q: missing ']'", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case inner == "*":
+				p.segments = append(p.segments, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				p.segments = append(p.segments, segment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid path %q: bad index %q", expr, inner)
+				}
+				p.segments = append(p.segments, segment{index: index, isIndex: true})
+			}
+
+		default:
+			// Allow "key.other" without a leading "$."
+			if len(p.segments) == 0 {
+				rest = "." + rest
+				continue
+			}
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", expr, rest)
+		}
+	}
+
+	return p, nil
+}
+
+// Find returns every value in doc matched by the path
+func (p *Path) Find(doc any) []any {
+	matches := []any{doc}
+	for _, seg := range p.segments {
+		var next []any
+		for _, node := range matches {
+			next = append(next, seg.children(node)...)
+		}
+		matches = next
+	}
+	return matches
+}
+
+// children returns the values of node selected by the segment
+func (s segment) children(node any) []any {
+	switch typed := node.(type) {
+	case map[string]any:
+		if s.wildcard {
+			values := make([]any, 0, len(typed))
+			for _, value := range typed {
+				values = append(values, value)
+			}
+			return values
+		}
+		if value, ok := typed[s.key]; ok && !s.isIndex {
+			return []any{value}
+		}
+	case []any:
+		if s.wildcard {
+			return typed
+		}
+		if s.isIndex {
+			index := s.index
+			if index < 0 {
+				index += len(typed)
+			}
+			if index >= 0 && index < len(typed) {
+				return []any{typed[index]}
+			}
+		}
+	}
+	return nil
+}
+
+// Contains reports whether the value at location, given as the object keys
+// (strings) and array indexes (ints) leading to it from the root, is matched
+// by the path or lies inside a matched value
+func (p *Path) Contains(location []any) bool {
+	if len(p.segments) > len(location) {
+		return false
+	}
+	for i, seg := range p.segments {
+		switch step := location[i].(type) {
+		case string:
+			if !seg.wildcard && (seg.isIndex || seg.key != step) {
+				return false
+			}
+		case int:
+			if !seg.wildcard && (!seg.isIndex || seg.index != step) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// FormatLocation renders a location, as taken by Contains, as a path such
+// as "$.items[0]['a.b']"
+func FormatLocation(location []any) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, step := range location {
+		switch step := step.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", step)
+		case string:
+			if step != "" && !strings.ContainsAny(step, ".[]'\" \t") {
+				b.WriteString("." + step)
+			} else {
+				fmt.Fprintf(&b, "['%s']", step)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Lookup returns the single value matched by expr in doc
+func Lookup(doc any, expr string) (any, error) {
+	p, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	matches := p.Find(doc)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("path %s not found", expr)
+	}
+	return matches[0], nil
+}
+
+// Decode parses a JSON body into the generic form used by Find
+func Decode(body []byte) (any, error) {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("body is not valid JSON: %w", err)
+	}
+	return doc, nil
+}
+
+// Format renders a matched value as a string: strings are returned as-is
+// and everything else as compact JSON
+func Format(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package jsonpath
+
+import (
+	"testing"
+)
+
+const sample = `{
+  "session": {"id": "abc123", "ttl": 3600},
+  "users": [
+    {"name": "hippo", "tags": ["a", "b"]},
+    {"name": "rhino", "tags": []}
+  ],
+  "weird key": true
+}`
+
+func TestLookup(t *testing.T) {
+	doc, err := Decode([]byte(sample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]string{
+		"$.session.id":       "abc123",
+		"session.ttl":        "3600",
+		"$.users[1].name":    "rhino",
+		"$.users[-1].name":   "rhino",
+		"$['users'][0].tags": `["a","b"]`,
+		"$[\"weird key\"]":   "true",
+		"$.users[*].name":    "hippo",
+		"$.users[0].tags[1]": "b",
+		"$.session":          `{"id":"abc123","ttl":3600}`,
+	}
+	for expr, want := range cases {
+		value, err := Lookup(doc, expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", expr, err)
+			continue
+		}
+		if got := Format(value); got != want {
+			t.Errorf("%s: expected %s, got %s", expr, want, got)
+		}
+	}
+}
+
+func TestFindWildcard(t *testing.T) {
+	doc, _ := Decode([]byte(sample))
+	p, err := Parse("$.users[*].name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matches := p.Find(doc); len(matches) != 2 {
+		t.Errorf("expected 2 matches, got %v", matches)
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	doc, _ := Decode([]byte(sample))
+	for _, expr := range []string{"$.missing", "$.users[5]", "$.session[0]", "$.users[x]", "$.users[0"} {
+		if _, err := Lookup(doc, expr); err == nil {
+			t.Errorf("%s: expected error", expr)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	cases := []struct {
+		expr     string
+		location []any
+		want     bool
+	}{
+		{"$.meta", []any{"meta", "requestId"}, true},
+		{"$.users[*].id", []any{"users", 3, "id"}, true},
+		{"$.users[1].id", []any{"users", 3, "id"}, false},
+		{"$.*.updated_at", []any{"order", "updated_at"}, true},
+		{"$.users[0].id", []any{"users"}, false},
+		{"$['content-type']", []any{"content-type"}, true},
+	}
+	for _, c := range cases {
+		p, err := Parse(c.expr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.expr, err)
+		}
+		if got := p.Contains(c.location); got != c.want {
+			t.Errorf("%s contains %s: expected %v, got %v", c.expr, FormatLocation(c.location), c.want, got)
+		}
+	}
+
+	if got := FormatLocation([]any{"items", 0, "a.b"}); got != "$.items[0]['a.b']" {
+		t.Errorf("unexpected location %s", got)
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pbidwell/hippocurl/utils"
+
+	"github.com/manifoldco/promptui"
+)
+
+// PassphraseEnvVar holds the passphrase of the secret store, so scripts can
+// use it without a prompt
+const PassphraseEnvVar = "HC_SECRET_PASSPHRASE"
+
+// TrustProjectEnvVar, when set, lets a project config resolve all of its
+// secret references
+const TrustProjectEnvVar = "HC_TRUST_PROJECT_CONFIG"
+
+// Reference matches "${kind:argument}" for the supported kinds of secret
+// references. The first group is the kind, the second its argument.
+var Reference = regexp.MustCompile(`\$\{(env|file|cmd|secret):([^}]+)\}`)
+
+// minMaskedLength is the length below which resolved values aren't masked,
+// as hiding every occurrence of a short value would garble unrelated text
+const minMaskedLength = 4
+
+var (
+	maskMu sync.Mutex
+	masked []string // Resolved secret values, longest first
+)
+
+// Resolver replaces secret references with their values. Each reference is
+// resolved once, and the secret store is only opened when referenced.
+type Resolver struct {
+	storePath  string
+	store      *Store
+	resolved   map[string]string
+	untrusted  error // Why references other than ${env:} are refused, nil when they're resolved
+}
+
+func NewResolver(storePath string) *Resolver {
+	return &Resolver{storePath: storePath, resolved: make(map[string]string)}
+}
+
+// Untrusted refuses ${file:}, ${cmd:} and ${secret:} references, failing
+// with reason, for configs that may not be trusted to read files, run
+// commands or send stored secrets to their hosts
+func (r *Resolver) Untrusted(reason error) {
+	r.untrusted = reason
+}
+
+// Resolve replaces every reference in s. Resolved values are registered to
+// be masked in output and logs.
+func (r *Resolver) Resolve(s string) (string, error) {
+	var resolveErr error
+	value := Reference.ReplaceAllStringFunc(s, func(match string) string {
+		if value, ok := r.resolved[match]; ok {
+			return value
+		}
+		groups := Reference.FindStringSubmatch(match)
+		value, err := r.lookup(groups[1], strings.TrimSpace(groups[2]))
+		if err != nil {
+			if resolveErr == nil {
+				resolveErr = fmt.Errorf("resolving %s: %w", match, err)
+			}
+			return match
+		}
+		r.resolved[match] = value
+		Register(value)
+		return value
+	})
+	return value, resolveErr
+}
+
+func (r *Resolver) lookup(kind, argument string) (string, error) {
+	if r.untrusted != nil && kind != "env" {
+		return "", r.untrusted
+	}
+	switch kind {
+	case "env":
+		value, ok := os.LookupEnv(argument)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", argument)
+		}
+		return value, nil
+	case "file":
+		if rest, ok := strings.CutPrefix(argument, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			argument = filepath.Join(home, rest)
+		}
+		data, err := os.ReadFile(argument)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case "cmd":
+		return runCommand(argument)
+	default:
+		if r.store == nil {
+			if r.storePath == "" || !StoreExists(r.storePath) {
+				return "", fmt.Errorf("secret %q not found, add it with \"hc secret set %s\"", argument, argument)
+			}
+			passphrase, err := ReadPassphrase(false)
+			if err != nil {
+				return "", err
+			}
+			if r.store, err = OpenStore(r.storePath, passphrase); err != nil {
+				return "", err
+			}
+		}
+		value, ok := r.store.Values[argument]
+		if !ok {
+			return "", fmt.Errorf("secret %q not found, add it with \"hc secret set %s\"", argument, argument)
+		}
+		return value, nil
+	}
+}
+
+// runCommand runs a shell command, such as "pass show api/token", and
+// returns its output without the trailing newline. It shares the terminal
+// so password managers can ask for their own passphrase.
+func runCommand(command string) (string, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	var stdout bytes.Buffer
+	cmd := exec.Command(shell, flag, command)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, &stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running %q: %w", command, err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// ReadPassphrase returns the passphrase of the secret store from
+// $HC_SECRET_PASSPHRASE, or asks for it. With confirm set, as when creating
+// the store, it's asked twice.
+func ReadPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(PassphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+	passphrase, err := promptPassphrase("Secret store passphrase")
+	if err != nil || !confirm {
+		return passphrase, err
+	}
+	again, err := promptPassphrase("Repeat the passphrase")
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", errors.New("the passphrases don't match")
+	}
+	return passphrase, nil
+}
+
+func promptPassphrase(label string) (string, error) {
+	input := promptui.Prompt{Label: label, Mask: '*', Stdout: os.Stderr}
+	passphrase, err := input.Run()
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase (set %s to skip the prompt): %w", PassphraseEnvVar, err)
+	}
+	return passphrase, nil
+}
+
+// Register marks value as a secret, to be masked by Mask
+func Register(value string) {
+	if len(value) < minMaskedLength {
+		return
+	}
+	maskMu.Lock()
+	defer maskMu.Unlock()
+	for _, known := range masked {
+		if known == value {
+			return
+		}
+	}
+	masked = append(masked, value)
+	sort.Slice(masked, func(i, j int) bool { return len(masked[i]) > len(masked[j]) })
+}
+
+// IsSecret reports whether value contains a registered secret
+func IsSecret(value string) bool {
+	return Mask(value) != value
+}
+
+// Mask replaces every registered secret in s
+func Mask(s string) string {
+	maskMu.Lock()
+	defer maskMu.Unlock()
+	for _, value := range masked {
+		s = strings.ReplaceAll(s, value, utils.Redacted)
+	}
+	return s
+}
+
+// MaskHeader returns a copy of header with registered secrets masked
+func MaskHeader(header http.Header) http.Header {
+	masked := make(http.Header, len(header))
+	for name, values := range header {
+		for _, value := range values {
+			masked[name] = append(masked[name], Mask(value))
+		}
+	}
+	return masked
+}
+
+// maskingWriter masks registered secrets in everything written through it
+type maskingWriter struct {
+	w io.Writer
+}
+
+// NewMaskingWriter returns a writer masking secrets before writing to w,
+// such as the log file
+func NewMaskingWriter(w io.Writer) io.Writer {
+	return maskingWriter{w: w}
+}
+
+func (m maskingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(m.w, Mask(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package secrets
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HC_TEST_TOKEN", "env-token")
+
+	resolver := NewResolver(filepath.Join(dir, StoreFileName))
+	value, err := resolver.Resolve("Bearer ${env:HC_TEST_TOKEN} ${file:" + tokenFile + "} ${other:x}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "Bearer env-token file-token ${other:x}" {
+		t.Errorf("unexpected value %q", value)
+	}
+	if runtime.GOOS != "windows" {
+		if value, err := resolver.Resolve("${cmd:echo cmd-token}"); err != nil || value != "cmd-token" {
+			t.Errorf("unexpected command output %q (%v)", value, err)
+		}
+	}
+
+	if _, err := resolver.Resolve("${env:HC_TEST_UNSET}"); err == nil || !strings.Contains(err.Error(), "HC_TEST_UNSET is not set") {
+		t.Errorf("expected an error for an unset variable, got %v", err)
+	}
+	if _, err := resolver.Resolve("${secret:api-token}"); err == nil || !strings.Contains(err.Error(), "hc secret set api-token") {
+		t.Errorf("expected an error for a missing secret, got %v", err)
+	}
+
+	untrusted := NewResolver("")
+	untrusted.Untrusted(errors.New("the config is not trusted"))
+	marker := filepath.Join(dir, "ran")
+	for _, ref := range []string{"${cmd:touch " + marker + "}", "${file:" + tokenFile + "}", "${secret:api-token}"} {
+		if _, err := untrusted.Resolve(ref); err == nil || !strings.Contains(err.Error(), "the config is not trusted") {
+			t.Errorf("expected %s to be refused, got %v", ref, err)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("expected the command not to run")
+	}
+	if value, err := untrusted.Resolve("${env:HC_TEST_TOKEN}"); err != nil || value != "env-token" {
+		t.Errorf("expected environment variables to be resolved, got %q (%v)", value, err)
+	}
+}
+
+func TestMask(t *testing.T) {
+	Register("abc")
+	Register("s3cr3t-value")
+	Register("s3cr3t")
+
+	if masked := Mask("token=s3cr3t-value&other=s3cr3t&short=abc"); masked != "token=REDACTED&other=REDACTED&short=abc" {
+		t.Errorf("unexpected masked text %q", masked)
+	}
+	header := http.Header{"Authorization": {"Bearer s3cr3t"}}
+	if masked := MaskHeader(header); masked.Get("Authorization") != "Bearer REDACTED" || header.Get("Authorization") != "Bearer s3cr3t" {
+		t.Errorf("expected a masked copy, got %v", masked)
+	}
+
+	var log strings.Builder
+	NewMaskingWriter(&log).Write([]byte("sent s3cr3t\n"))
+	if log.String() != "sent REDACTED\n" {
+		t.Errorf("unexpected log line %q", log.String())
+	}
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StoreFileName)
+	store, err := OpenStore(path, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Values["api-token"] = "abc123"
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "abc123") || strings.Contains(string(data), "api-token") {
+		t.Errorf("expected the store to be encrypted, got %s", data)
+	}
+	if store, err = OpenStore(path, "correct horse"); err != nil || store.Values["api-token"] != "abc123" {
+		t.Errorf("unexpected values %v (%v)", store.Values, err)
+	}
+	if _, err := OpenStore(path, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	t.Setenv(PassphraseEnvVar, "correct horse")
+	value, err := NewResolver(path).Resolve("${secret:api-token}")
+	if err != nil || value != "abc123" {
+		t.Errorf("unexpected secret %q (%v)", value, err)
+	}
+}
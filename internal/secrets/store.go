@@ -0,0 +1,140 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// StoreFileName is the file, inside the hc config directory, that holds the
+// encrypted secrets
+const StoreFileName = "secrets.json"
+
+const (
+	storeVersion  = 1
+	keyIterations = 600000      // PBKDF2-SHA256 iterations deriving the key from the passphrase
+	keySize       = sha256.Size // AES-256
+	saltSize      = 16
+)
+
+// ErrWrongPassphrase is returned when the store can't be decrypted
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted secret store")
+
+// Store is a set of named secrets, encrypted at rest with AES-GCM using a
+// key derived from a passphrase
+type Store struct {
+	path   string
+	salt   []byte
+	key    []byte
+	Values map[string]string
+}
+
+// storeFile is the JSON layout of the store on disk
+type storeFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"` // The encrypted JSON object of secrets
+}
+
+// StoreExists reports whether a store has been saved at path
+func StoreExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// OpenStore decrypts the store at path with passphrase. A missing file yields
+// an empty store, encrypted with passphrase when saved.
+func OpenStore(path, passphrase string) (*Store, error) {
+	if passphrase == "" {
+		return nil, errors.New("a passphrase is required")
+	}
+	store := &Store{path: path, Values: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		store.salt = make([]byte, saltSize)
+		if _, err := rand.Read(store.salt); err != nil {
+			return nil, err
+		}
+		store.key = deriveKey(passphrase, store.salt)
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading secret store: %w", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing secret store %s: %w", path, err)
+	}
+	if file.Version != storeVersion {
+		return nil, fmt.Errorf("secret store %s has unsupported version %d", path, file.Version)
+	}
+	store.salt = file.Salt
+	store.key = deriveKey(passphrase, file.Salt)
+	aead, err := newAEAD(store.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if err := json.Unmarshal(plaintext, &store.Values); err != nil {
+		return nil, fmt.Errorf("parsing secret store %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Save encrypts the store with a fresh nonce and writes it to disk, only
+// readable by the current user
+func (s *Store) Save() error {
+	plaintext, err := json.Marshal(s.Values)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(s.key)
+	if err != nil {
+		return err
+	}
+	file := storeFile{Version: storeVersion, Salt: s.salt, Nonce: make([]byte, aead.NonceSize())}
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = aead.Seal(nil, file.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("writing secret store: %w", err)
+	}
+	return nil
+}
+
+func deriveKey(passphrase string, salt []byte) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, keyIterations, keySize, sha256.New)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
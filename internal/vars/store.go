@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package vars
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// StoreFileName is the file, inside the hc config directory, that holds
+// values extracted from previous responses
+const StoreFileName = "variables.json"
+
+// scopes holds stored variables by service, environment and name
+type scopes map[string]map[string]map[string]string
+
+// Store is the set of variables persisted between hc invocations for one
+// service and environment, so values captured against one environment are
+// never sent to another
+type Store struct {
+	path    string
+	service string
+	env     string
+	scopes  scopes
+	Values  map[string]string
+}
+
+// LoadStore reads the variables of service and env from the store at path.
+// A missing file yields an empty store.
+func LoadStore(path, service, env string) (*Store, error) {
+	all, err := readScopes(path)
+	if err != nil {
+		return nil, err
+	}
+	if all[service] == nil {
+		all[service] = make(map[string]map[string]string)
+	}
+	if all[service][env] == nil {
+		all[service][env] = make(map[string]string)
+	}
+	return &Store{path: path, service: service, env: env, scopes: all, Values: all[service][env]}, nil
+}
+
+func (s *Store) Set(name, value string) {
+	s.Values[name] = value
+}
+
+// Save writes the store back to disk. Extracted values are often session
+// ids or tokens, so the file is only readable by the current user.
+func (s *Store) Save() error {
+	return writeScopes(s.path, s.scopes)
+}
+
+func readScopes(path string) (scopes, error) {
+	all := make(scopes)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading variable store: %w", err)
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("parsing variable store %s: %w", path, err)
+	}
+	return all, nil
+}
+
+// writeScopes saves all to path, leaving out environments and services
+// without variables
+func writeScopes(path string, all scopes) error {
+	kept := make(scopes)
+	for service, envs := range all {
+		for env, values := range envs {
+			if len(values) == 0 {
+				continue
+			}
+			if kept[service] == nil {
+				kept[service] = make(map[string]map[string]string)
+			}
+			kept[service][env] = values
+		}
+	}
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing variable store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package vars
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envPrefix marks references to process environment variables, e.g. {{env.HOME}}
+const envPrefix = "env."
+
+// maxDepth limits how deeply variable values referencing other variables are expanded
+const maxDepth = 10
+
+// Placeholder matches "{{name}}", allowing whitespace around the name. The
+// first group is the name.
+var Placeholder = regexp.MustCompile(`{{\s*([A-Za-z0-9_.\-]+)\s*}}`)
+
+// Resolver looks up variables across a list of scopes. Earlier scopes take
+// precedence over later ones, so callers pass the most specific scope first.
+type Resolver struct {
+	scopes     []map[string]string
+	transforms map[int]func(string) string // By scope index, see Transform
+}
+
+func NewResolver(scopes ...map[string]string) *Resolver {
+	return &Resolver{scopes: scopes, transforms: make(map[int]func(string) string)}
+}
+
+// Transform sets a function applied to the values of the scope at index as
+// they are looked up, e.g. to resolve secret references only in values that
+// come from the config
+func (r *Resolver) Transform(index int, transform func(string) string) *Resolver {
+	r.transforms[index] = transform
+	return r
+}
+
+// Lookup returns the value of a variable, checking the process environment
+// for "env." prefixed names
+func (r *Resolver) Lookup(name string) (string, bool) {
+	if envName, ok := strings.CutPrefix(name, envPrefix); ok {
+		return os.LookupEnv(envName)
+	}
+	for i, scope := range r.scopes {
+		if value, ok := scope[name]; ok {
+			if transform := r.transforms[i]; transform != nil {
+				value = transform(value)
+			}
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Expander expands placeholders in a series of strings, collecting every
+// unresolved variable so they can be reported together
+type Expander struct {
+	resolver *Resolver
+	missing  map[string]bool
+	err      error
+}
+
+func (r *Resolver) Expander() *Expander {
+	return &Expander{resolver: r, missing: make(map[string]bool)}
+}
+
+// Expand replaces all placeholders in s. Unresolved placeholders are left
+// in place and reported by Err.
+func (e *Expander) Expand(s string) string {
+	return e.expand(s, nil)
+}
+
+func (e *Expander) expand(s string, stack []string) string {
+	return Placeholder.ReplaceAllStringFunc(s, func(match string) string {
+		name := Placeholder.FindStringSubmatch(match)[1]
+
+		value, ok := e.resolver.Lookup(name)
+		if !ok {
+			e.missing[name] = true
+			return match
+		}
+
+		for _, seen := range stack {
+			if seen == name {
+				e.fail(fmt.Errorf("variable cycle: %s -> %s", strings.Join(stack, " -> "), name))
+				return match
+			}
+		}
+		if len(stack) >= maxDepth {
+			e.fail(fmt.Errorf("variable %q nested deeper than %d levels", name, maxDepth))
+			return match
+		}
+		return e.expand(value, append(stack, name))
+	})
+}
+
+func (e *Expander) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// Err returns a *MissingError listing every unresolved variable seen so far,
+// or the first cycle detected while expanding
+func (e *Expander) Err() error {
+	if e.err != nil {
+		return e.err
+	}
+	if len(e.missing) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(e.missing))
+	for name := range e.missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &MissingError{Names: names}
+}
+
+// MissingError reports variables referenced by a template but not defined
+type MissingError struct {
+	Names []string
+}
+
+func (m *MissingError) Error() string {
+	return fmt.Sprintf("unresolved variables: %s", strings.Join(m.Names, ", "))
+}
+
+// ParseAssignments parses "key=value" pairs, as passed with --var flags
+func ParseAssignments(assignments []string) (map[string]string, error) {
+	values := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		key, value, ok := strings.Cut(assignment, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid assignment %q, expected key=value", assignment)
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package vars
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandPrecedence(t *testing.T) {
+	cli := map[string]string{"user": "cli-user"}
+	env := map[string]string{"user": "env-user", "tenant": "acme"}
+	service := map[string]string{"tenant": "default", "version": "v2"}
+
+	e := NewResolver(cli, env, service).Expander()
+	got := e.Expand("/{{version}}/{{ tenant }}/users/{{user}}")
+	if err := e.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/v2/acme/users/cli-user"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestExpandProcessEnvironment(t *testing.T) {
+	t.Setenv("HC_TEST_HOME", "/home/hippo")
+
+	e := NewResolver().Expander()
+	if got := e.Expand("{{env.HC_TEST_HOME}}/data"); got != "/home/hippo/data" {
+		t.Errorf("unexpected expansion: %q", got)
+	}
+	if err := e.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestExpandNested(t *testing.T) {
+	e := NewResolver(map[string]string{"host": "api.{{domain}}", "domain": "example.com"}).Expander()
+	if got := e.Expand("https://{{host}}"); got != "https://api.example.com" {
+		t.Errorf("unexpected expansion: %q", got)
+	}
+}
+
+func TestExpandReportsAllMissing(t *testing.T) {
+	e := NewResolver(map[string]string{"known": "ok"}).Expander()
+	e.Expand("/{{known}}/{{b}}")
+	e.Expand("{{a}} {{env.HC_TEST_UNSET_VARIABLE}}")
+
+	var missing *MissingError
+	if !errors.As(e.Err(), &missing) {
+		t.Fatalf("expected MissingError, got %v", e.Err())
+	}
+	want := []string{"a", "b", "env.HC_TEST_UNSET_VARIABLE"}
+	if !reflect.DeepEqual(missing.Names, want) {
+		t.Errorf("expected missing %v, got %v", want, missing.Names)
+	}
+}
+
+func TestExpandCycle(t *testing.T) {
+	e := NewResolver(map[string]string{"a": "{{b}}", "b": "{{a}}"}).Expander()
+	e.Expand("{{a}}")
+	if e.Err() == nil {
+		t.Errorf("expected cycle error")
+	}
+}
+
+func TestParseAssignments(t *testing.T) {
+	values, err := ParseAssignments([]string{"id=42", "filter=a=b", "empty="})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"id": "42", "filter": "a=b", "empty": ""}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("expected %v, got %v", want, values)
+	}
+
+	if _, err := ParseAssignments([]string{"novalue"}); err == nil {
+		t.Errorf("expected error for assignment without '='")
+	}
+}
+
+func TestStoreScopes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StoreFileName)
+
+	staging, _ := LoadStore(path, "Users", "staging")
+	staging.Set("session", "s1")
+	if err := staging.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	prod, _ := LoadStore(path, "Users", "prod")
+	if len(prod.Values) != 0 {
+		t.Errorf("expected staging values to stay out of prod, got %v", prod.Values)
+	}
+	prod.Set("session", "p1")
+	if err := prod.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if staging, _ = LoadStore(path, "Users", "staging"); staging.Values["session"] != "s1" {
+		t.Errorf("expected staging session to be kept, got %v", staging.Values)
+	}
+}
@@ -5,16 +5,24 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/history"
+	"github.com/pbidwell/hippocurl/internal/jsonpath"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+	"github.com/pbidwell/hippocurl/internal/vars"
+	"github.com/pbidwell/hippocurl/modules"
 	"github.com/pbidwell/hippocurl/utils"
 
 	"github.com/briandowns/spinner"
@@ -22,9 +30,45 @@ import (
 )
 
 // APIModule implements the HippoModule interface
-type APIModule struct{}
+type APIModule struct {
+	Overrides Overrides
+	AsCurl    bool   // Print the request as a curl command instead of sending it
+	Redact    bool   // Hide secrets in the printed curl command
+	Output    string // Non-interactive output format, one of OutputFormats
+	Snapshot  string // Save or check a snapshot of the response, one of SnapshotModes
+}
+
+const (
+	actionSend     = "Send request"
+	actionShowCurl = "Show as cURL command"
+)
+
+var alogger = log.New(io.Discard, "", 0)
+
+// Setup points the api package at the app's logger and state directory.
+// Modules sending requests through this package call it before Send.
+func Setup(app *config.App) {
+	alogger = app.Logger
+	tokenCachePath = filepath.Join(app.ConfigDir, tokenCacheFileName)
+	secretResolver = newSecretResolver(app)
+	var settings config.History
+	if app.APIConfig != nil {
+		globalPolicy = app.APIConfig.Policy
+		settings = app.APIConfig.History
+	}
+	historyStore = history.NewStore(filepath.Join(app.ConfigDir, history.FileName), settings)
+}
 
-var alogger *log.Logger
+// newSecretResolver returns the resolver for the app's config. A config
+// found by walking up from the working directory may come from a cloned
+// repository, so it may only read environment variables unless trusted.
+func newSecretResolver(app *config.App) *secrets.Resolver {
+	resolver := secrets.NewResolver(filepath.Join(app.ConfigDir, secrets.StoreFileName))
+	if app.APIConfigSource == config.SourceProject && os.Getenv(secrets.TrustProjectEnvVar) == "" {
+		resolver.Untrusted(fmt.Errorf("only ${env:} references are resolved in the project config %s, set $%s to trust it", app.APIConfigPath, secrets.TrustProjectEnvVar))
+	}
+	return resolver
+}
 
 func (a APIModule) Name() string {
 	return "api"
@@ -38,7 +82,11 @@ func (a APIModule) Use() string {
 	return fmt.Sprintf("%s [<serviceName> <routeName> <environmentName>]", a.Name())
 }
 
-func (a APIModule) Execute(app *config.App, args []string) {
+func (a APIModule) Execute(ctx context.Context, app *config.App, args []string) error {
+	if a.Output != "" {
+		return a.executeOutput(ctx, app, args)
+	}
+
 	// Module banner
 	utils.Print(a.Name(), utils.ModuleTitle)
 
@@ -48,7 +96,7 @@ func (a APIModule) Execute(app *config.App, args []string) {
 	// 	return
 	// }
 
-	alogger = app.Logger
+	Setup(app)
 
 	var serviceName, routeName, envName string
 	if len(args) > 0 {
@@ -63,78 +111,203 @@ func (a APIModule) Execute(app *config.App, args []string) {
 
 	service, route, env, interactive := getServiceDetails(config, serviceName, routeName, envName)
 	if service == nil || route == nil || env == nil {
-		utils.Print("Invalid selection.", utils.NormalText)
-		return
+		if interactive {
+			return modules.Exit(modules.ExitUsage, nil)
+		}
+		return modules.Usagef("invalid selection: %s %s %s", serviceName, routeName, envName)
+	}
+
+	store, err := vars.LoadStore(filepath.Join(app.ConfigDir, vars.StoreFileName), service.Name, env.Name)
+	if err != nil {
+		return fmt.Errorf("loading variable store: %w", err)
 	}
 
-	url := env.BaseURL + route.Path
-	performHTTPRequest(url, route.Method, env.Headers, route.Body)
+	req, err := PrepareRequest(service, route, env, a.Overrides, store.Values)
+	if err != nil {
+		return fmt.Errorf("preparing request: %w", err)
+	}
+
+	asCurl := a.AsCurl
+	if interactive && !asCurl {
+		action, ok := promptForAction()
+		if !ok {
+			return modules.Exit(modules.ExitUsage, nil)
+		}
+		asCurl = action == actionShowCurl
+	}
+	if asCurl {
+		if err := printCurl(ctx, req, a.Redact); err != nil {
+			return err
+		}
+		if interactive {
+			utils.Print(fmt.Sprintf("Use \"hc %s %s %s %s --as-curl\" to print this command again.", a.Name(), service.Name, route.Name, env.Name), utils.Hint)
+		}
+		return nil
+	}
+
+	var ignore []*jsonpath.Path
+	if a.Snapshot != "" {
+		if ignore, err = parseIgnorePaths(route.Ignore); err != nil {
+			return modules.Usagef("route %s: %v", route.Name, err)
+		}
+	}
+
+	resp, err := performHTTPRequest(ctx, req, newHistoryEntry(service, route, env, a.Overrides))
+	if err != nil {
+		return err
+	}
+	if len(route.Extract) > 0 {
+		extractVariables(route.Extract, resp, store)
+	}
+	var results []AssertionResult
+	if route.Assert != nil {
+		results = CheckAssertions(route.Assert, resp)
+		printAssertions(results)
+	}
+	if a.Snapshot != "" {
+		matched, err := a.checkSnapshot(snapshotPath(app.ConfigDir, service.Name, route.Name, env.Name), resp, ignore)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return modules.Exit(modules.ExitAssertion, nil)
+		}
+	}
 	if interactive {
 		utils.Print(fmt.Sprintf("Use \"hc %s %s %s %s\" to re-try this API call.", a.Name(), service.Name, route.Name, env.Name), utils.Hint)
 	}
+	return responseError(resp, results)
 }
 
 func (e APIModule) Logo() string {
 	return "📤"
 }
 
-func performHTTPRequest(url string, method string, headers map[string]string, body string) {
-	spinner := spinner.New(spinner.CharSets[35], 100*time.Millisecond)
-	var reqBody *bytes.Reader
-	if body != "" {
-		reqBody = bytes.NewReader([]byte(body))
-	} else {
-		reqBody = bytes.NewReader(nil)
+// executeOutput sends the request without prompts, spinners or banners and
+// writes it to stdout in the requested format
+func (a APIModule) executeOutput(ctx context.Context, app *config.App, args []string) error {
+	Setup(app)
+
+	if len(args) < 3 {
+		return modules.Usagef("--output requires a service, route and environment")
+	}
+	service, route, env, _ := getServiceDetails(app.APIConfig, args[0], args[1], args[2])
+	if service == nil || route == nil || env == nil {
+		return modules.Usagef("unknown service, route or environment: %s %s %s", args[0], args[1], args[2])
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	store, err := vars.LoadStore(filepath.Join(app.ConfigDir, vars.StoreFileName), service.Name, env.Name)
+	if err != nil {
+		return fmt.Errorf("loading variable store: %w", err)
+	}
+	prepared, err := PrepareRequest(service, route, env, a.Overrides, store.Values)
+	if err != nil {
+		return fmt.Errorf("preparing request: %w", err)
+	}
+	doc := &outputDocument{}
+	var sent *http.Request
+	resp, err := prepared.send(ctx, func(attempt Attempt) {
+		sent = attempt.Request
+		if attempt.Of > 1 {
+			doc.Attempts = append(doc.Attempts, newOutputAttempt(attempt))
+		}
+	})
+	if sent == nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	doc.Request = newOutputRequest(sent, prepared.Body, a.Redact)
+	doc.Request.Service, doc.Request.Route, doc.Request.Environment = service.Name, route.Name, env.Name
+	if ctx.Err() == nil {
+		recordHistory(newHistoryEntry(service, route, env, a.Overrides), sent, prepared.Body, resp, err)
+	}
 	if err != nil {
-		fmt.Printf("Error creating request: %v\n", err)
-		return
+		doc.Error = err.Error()
+		if a.Output == OutputJSON {
+			writeOutput(os.Stdout, a.Output, doc, nil)
+		}
+		return modules.Exit(modules.ExitTransport, fmt.Errorf("making request: %w", err))
 	}
 
-	// Add headers to the request
-	for key, value := range headers {
-		req.Header.Set(key, value)
+	doc.Response = newOutputResponse(resp)
+	doc.Timings = newOutputTimings(resp)
+	if len(route.Extract) > 0 {
+		values, errs := ApplyExtractions(route.Extract, resp, store)
+		for _, err := range errs {
+			alogger.Printf("Error extracting variable: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error extracting variable: %v\n", err)
+		}
+		if len(values) > 0 {
+			doc.Extracted = values
+		}
+	}
+	if route.Assert != nil {
+		doc.Assertions = CheckAssertions(route.Assert, resp)
 	}
 
-	utils.Print("HTTP Request", utils.Header1)
-	utils.Print("URL", utils.Header2)
-	utils.Print(url, utils.NormalText)
-	utils.Print("Headers", utils.Header2)
-	utils.PrintHeaders(req.Header)
-	utils.Print("Body", utils.Header2)
-	reqBodyBytes, err := io.ReadAll(req.Body)
-	if err != nil {
-		alogger.Printf("Error parsing request body: %v\n", err)
-		fmt.Printf("Error parsing request body: %v\n", err)
-		return
+	if err := writeOutput(os.Stdout, a.Output, doc, resp); err != nil {
+		return fmt.Errorf("writing output: %w", err)
 	}
-	printFormattedResponse(reqBodyBytes, req.Header.Get("Content-Type"))
-	spinner.Start()
+	return responseError(resp, doc.Assertions)
+}
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
-	spinner.Stop()
-	if err != nil {
-		fmt.Printf("Error making request: %v\n", err)
-		return
+// responseError returns the exit status for a received response: the
+// status class for 4xx and 5xx responses, then failed assertions
+func responseError(resp *Response, results []AssertionResult) error {
+	if code := modules.StatusExitCode(resp.StatusCode); code != modules.ExitOK {
+		return modules.Exit(code, nil)
 	}
-	defer resp.Body.Close()
+	if !AssertionsPassed(results) {
+		return modules.Exit(modules.ExitAssertion, nil)
+	}
+	return nil
+}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+// performHTTPRequest sends the request and prints both the request and the
+// response. The exchange is recorded in the history as entry, if not nil.
+func performHTTPRequest(ctx context.Context, prepared *PreparedRequest, entry *history.Entry) (*Response, error) {
+	spinner := spinner.New(spinner.CharSets[35], 100*time.Millisecond)
+
+	// The request is printed once its first attempt is sent, as it is only
+	// built and authenticated then
+	var sent *http.Request
+	spinner.Start()
+	resp, err := prepared.send(ctx, func(attempt Attempt) {
+		spinner.Stop()
+		if sent == nil {
+			printRequest(attempt.Request, prepared.Body)
+		}
+		sent = attempt.Request
+		if attempt.Of > 1 {
+			utils.Print(attempt.String(), utils.NormalText)
+		}
+		spinner.Start()
+	})
+	spinner.Stop()
+	if sent == nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if ctx.Err() == nil {
+		recordHistory(entry, sent, prepared.Body, resp, err)
+	}
 	if err != nil {
-		fmt.Printf("Error reading response: %v\n", err)
-		return
+		if ctx.Err() != nil {
+			alogger.Printf("Request to %s cancelled\n", sent.URL)
+		}
+		return nil, modules.Exit(modules.ExitTransport, fmt.Errorf("making request: %w", err))
 	}
 
 	utils.Print("HTTP Response", utils.Header1)
 	utils.Print("Status", utils.Header2)
 	fmt.Println(resp.Status)
 	utils.Print("Headers", utils.Header2)
-	utils.PrintHeaders(resp.Header)
+	utils.PrintHeaders(secrets.MaskHeader(resp.Headers))
 	utils.Print("Body", utils.Header2)
-	printFormattedResponse(bodyBytes, resp.Header.Get("Content-Type"))
+	PrintFormattedBody([]byte(secrets.Mask(string(resp.Body))), resp.Headers.Get("Content-Type"))
+	if resp.Timings != nil {
+		printTimings(resp.Timings)
+	}
+
+	return resp, nil
 }
 
 func getServiceDetails(apiConfig *config.APIConfig, serviceName, routeName, envName string) (*config.Service, *config.Route, *config.Environment, bool) {
@@ -216,7 +389,46 @@ func promptUserForServiceDetails(config *config.APIConfig) (*config.Service, *co
 	return service, route, environment
 }
 
-func printFormattedResponse(body []byte, contentType string) {
+// promptForAction asks whether to send the selected request or print it
+// as a curl command
+func promptForAction() (string, bool) {
+	actionPrompt := promptui.Select{
+		Label: "Select an Action",
+		Items: []string{actionSend, actionShowCurl},
+	}
+	_, action, err := actionPrompt.Run()
+	if err != nil {
+		utils.Print("Selection cancelled.", utils.NormalText)
+		return "", false
+	}
+	return action, true
+}
+
+// printRequest prints a request as sent, with the given body
+func printRequest(req *http.Request, body string) {
+	utils.Print("HTTP Request", utils.Header1)
+	utils.Print("URL", utils.Header2)
+	utils.Print(secrets.Mask(req.URL.String()), utils.NormalText)
+	utils.Print("Headers", utils.Header2)
+	utils.PrintHeaders(secrets.MaskHeader(req.Header))
+	utils.Print("Body", utils.Header2)
+	PrintFormattedBody([]byte(secrets.Mask(body)), req.Header.Get("Content-Type"))
+}
+
+// printCurl prints the fully resolved request as a curl command
+func printCurl(ctx context.Context, prepared *PreparedRequest, redact bool) error {
+	req, err := prepared.newHTTPRequest(ctx)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	utils.Print("cURL Command", utils.Header1)
+	fmt.Println(renderCurl(req, prepared.Body, redact))
+	return nil
+}
+
+// PrintFormattedBody prints a body, indenting JSON
+func PrintFormattedBody(body []byte, contentType string) {
 	switch {
 	case strings.Contains(contentType, "json"):
 		var prettyJSON bytes.Buffer
@@ -0,0 +1,38 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+)
+
+func TestNewSecretResolverDistrustsProjectConfigs(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ref := "${file:" + tokenFile + "}"
+	t.Setenv(secrets.TrustProjectEnvVar, "")
+
+	user := &config.App{ConfigDir: t.TempDir(), APIConfigSource: config.SourceDefault}
+	if value, err := newSecretResolver(user).Resolve(ref); err != nil || value != "file-token" {
+		t.Errorf("expected the user's config to read files, got %q (%v)", value, err)
+	}
+
+	project := &config.App{ConfigDir: t.TempDir(), APIConfigSource: config.SourceProject, APIConfigPath: "/repo/.hc/api_config.yml"}
+	if _, err := newSecretResolver(project).Resolve(ref); err == nil || !strings.Contains(err.Error(), secrets.TrustProjectEnvVar) {
+		t.Errorf("expected the project config not to read files, got %v", err)
+	}
+
+	t.Setenv(secrets.TrustProjectEnvVar, "1")
+	if value, err := newSecretResolver(project).Resolve(ref); err != nil || value != "file-token" {
+		t.Errorf("expected a trusted project config to read files, got %q (%v)", value, err)
+	}
+}
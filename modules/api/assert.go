@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/jsonpath"
+	"github.com/pbidwell/hippocurl/utils"
+
+	"github.com/fatih/color"
+)
+
+// AssertionResult is the outcome of a single check of an assertion block
+type AssertionResult struct {
+	Check  string `json:"check"` // e.g. "status 2xx"
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"` // Why the check failed
+}
+
+// CheckAssertions evaluates an assertion block against a response. Checks
+// are returned in a stable order: status, headers, header regexes, JSON
+// paths, body substrings and latency.
+func CheckAssertions(assert *config.Assertion, resp *Response) []AssertionResult {
+	if assert == nil {
+		return nil
+	}
+
+	var results []AssertionResult
+	add := func(check string, passed bool, detail string, args ...any) {
+		result := AssertionResult{Check: check, Passed: passed}
+		if !passed {
+			result.Detail = fmt.Sprintf(detail, args...)
+		}
+		results = append(results, result)
+	}
+
+	if assert.Status != "" {
+		ok, err := StatusMatches(assert.Status, resp.StatusCode)
+		if err != nil {
+			add("status "+assert.Status, false, "%v", err)
+		} else {
+			add("status "+assert.Status, ok, "got %d", resp.StatusCode)
+		}
+	}
+
+	for _, name := range sortedKeys(assert.Headers) {
+		want := assert.Headers[name]
+		got := resp.Headers.Get(name)
+		add(fmt.Sprintf("header %s = %s", name, want), got == want, "got %q", got)
+	}
+
+	for _, name := range sortedKeys(assert.HeaderRegex) {
+		pattern := assert.HeaderRegex[name]
+		check := fmt.Sprintf("header %s ~ %s", name, pattern)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			add(check, false, "invalid regex: %v", err)
+			continue
+		}
+		got := resp.Headers.Get(name)
+		add(check, re.MatchString(got), "got %q", got)
+	}
+
+	if len(assert.JSON) > 0 {
+		doc, docErr := jsonpath.Decode(resp.Body)
+		for _, path := range sortedKeys(assert.JSON) {
+			want := assert.JSON[path]
+			check := fmt.Sprintf("json %s = %s", path, jsonpath.Format(want))
+			if docErr != nil {
+				add(check, false, "%v", docErr)
+				continue
+			}
+			got, err := jsonpath.Lookup(doc, path)
+			if err != nil {
+				add(check, false, "%v", err)
+				continue
+			}
+			add(check, jsonEqual(got, want), "got %s", jsonpath.Format(got))
+		}
+	}
+
+	for _, substr := range assert.BodyContains {
+		add(fmt.Sprintf("body contains %q", substr), strings.Contains(string(resp.Body), substr), "not found in body")
+	}
+
+	if assert.MaxLatency > 0 {
+		add(fmt.Sprintf("latency <= %s", assert.MaxLatency), resp.Latency <= assert.MaxLatency, "took %s", resp.Latency.Round(time.Millisecond))
+	}
+
+	return results
+}
+
+// AssertionsPassed reports whether every result passed
+func AssertionsPassed(results []AssertionResult) bool {
+	for _, result := range results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// StatusMatches checks a status code against "200", "2xx" or "200-299"
+func StatusMatches(spec string, code int) (bool, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+
+	if len(spec) == 3 && strings.HasSuffix(spec, "xx") {
+		class, err := strconv.Atoi(spec[:1])
+		if err != nil {
+			return false, fmt.Errorf("invalid status %q", spec)
+		}
+		return code/100 == class, nil
+	}
+
+	if low, high, ok := strings.Cut(spec, "-"); ok {
+		from, err1 := strconv.Atoi(strings.TrimSpace(low))
+		to, err2 := strconv.Atoi(strings.TrimSpace(high))
+		if err1 != nil || err2 != nil {
+			return false, fmt.Errorf("invalid status range %q", spec)
+		}
+		return code >= from && code <= to, nil
+	}
+
+	want, err := strconv.Atoi(spec)
+	if err != nil {
+		return false, fmt.Errorf("invalid status %q", spec)
+	}
+	return code == want, nil
+}
+
+// jsonEqual compares a decoded JSON value to an expected value from the
+// config, normalizing both through JSON so 7 and 7.0 compare equal
+func jsonEqual(got, want any) bool {
+	normalize := func(v any) any {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		var out any
+		if err := json.Unmarshal(data, &out); err != nil {
+			return v
+		}
+		return out
+	}
+	return reflect.DeepEqual(normalize(got), normalize(want))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printAssertions prints each assertion result on its own line
+func printAssertions(results []AssertionResult) {
+	utils.Print("Assertions", utils.Header2)
+	for _, result := range results {
+		if result.Passed {
+			color.New(color.FgGreen).Printf("PASS %s\n", result.Check)
+		} else {
+			color.New(color.FgRed).Printf("FAIL %s (%s)\n", result.Check, result.Detail)
+		}
+	}
+}
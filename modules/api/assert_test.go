@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+func TestCheckAssertions(t *testing.T) {
+	resp := &Response{
+		StatusCode: http.StatusCreated,
+		Headers:    http.Header{"Content-Type": {"application/json"}, "X-Request-Id": {"req-123"}},
+		Body:       []byte(`{"user": {"id": 7, "name": "hippo", "tags": ["a"]}}`),
+		Latency:    120 * time.Millisecond,
+	}
+	assert := &config.Assertion{
+		Status:       "2xx",
+		Headers:      map[string]string{"Content-Type": "application/json"},
+		HeaderRegex:  map[string]string{"X-Request-Id": `^req-\d+$`},
+		JSON:         map[string]any{"$.user.id": 7, "$.user.name": "hippo", "$.user.tags": []any{"a"}},
+		BodyContains: []string{"hippo"},
+		MaxLatency:   time.Second,
+	}
+
+	results := CheckAssertions(assert, resp)
+	if len(results) != 8 {
+		t.Fatalf("expected 8 results, got %d: %+v", len(results), results)
+	}
+	if !AssertionsPassed(results) {
+		t.Errorf("expected all assertions to pass, got %+v", results)
+	}
+}
+
+func TestCheckAssertionsFailures(t *testing.T) {
+	resp := &Response{
+		StatusCode: http.StatusNotFound,
+		Headers:    http.Header{},
+		Body:       []byte(`{"error": "not found"}`),
+		Latency:    2 * time.Second,
+	}
+	assert := &config.Assertion{
+		Status:       "200-204",
+		Headers:      map[string]string{"X-Missing": "value"},
+		JSON:         map[string]any{"$.error": "gone", "$.missing": 1},
+		BodyContains: []string{"hippo"},
+		MaxLatency:   time.Second,
+	}
+
+	results := CheckAssertions(assert, resp)
+	for _, result := range results {
+		if result.Passed {
+			t.Errorf("expected %q to fail", result.Check)
+		}
+		if result.Detail == "" {
+			t.Errorf("expected %q to explain its failure", result.Check)
+		}
+	}
+	if len(results) != 6 {
+		t.Errorf("expected 6 results, got %d", len(results))
+	}
+}
+
+func TestStatusMatches(t *testing.T) {
+	cases := []struct {
+		spec string
+		code int
+		want bool
+	}{
+		{"200", 200, true},
+		{"200", 201, false},
+		{"2xx", 204, true},
+		{"4XX", 500, false},
+		{"200-299", 299, true},
+		{"200 - 299", 300, false},
+	}
+	for _, c := range cases {
+		got, err := StatusMatches(c.spec, c.code)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.spec, err)
+		}
+		if got != c.want {
+			t.Errorf("%s against %d: expected %v", c.spec, c.code, c.want)
+		}
+	}
+
+	for _, spec := range []string{"ok", "axx", "200-abc"} {
+		if _, err := StatusMatches(spec, 200); err == nil {
+			t.Errorf("%s: expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+)
+
+// Authenticator decorates an outgoing request with the credentials
+// configured for an environment.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// authenticatorFactory builds an Authenticator from an auth config block
+type authenticatorFactory func(auth config.Auth) (Authenticator, error)
+
+// authenticators maps each supported auth type to its factory.
+// New auth types only need to register themselves here.
+var authenticators = map[string]authenticatorFactory{
+	"":       newNoAuth,
+	"none":   newNoAuth,
+	"basic":  newBasicAuth,
+	"bearer": newBearerAuth,
+}
+
+// newAuthenticator returns the Authenticator matching auth.Type
+func newAuthenticator(auth config.Auth) (Authenticator, error) {
+	factory, ok := authenticators[strings.ToLower(strings.TrimSpace(auth.Type))]
+	if !ok {
+		return nil, fmt.Errorf("unknown auth type %q", auth.Type)
+	}
+	return factory(auth)
+}
+
+// noAuth leaves the request untouched
+type noAuth struct{}
+
+func newNoAuth(config.Auth) (Authenticator, error) {
+	return noAuth{}, nil
+}
+
+func (noAuth) Authenticate(*http.Request) error {
+	return nil
+}
+
+// basicAuth sets an "Authorization: Basic" header
+type basicAuth struct {
+	username string
+	password string
+}
+
+func newBasicAuth(auth config.Auth) (Authenticator, error) {
+	if auth.Username == "" {
+		return nil, errors.New("basic auth requires a username")
+	}
+	return basicAuth{username: auth.Username, password: auth.Password}, nil
+}
+
+func (b basicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(b.username, b.password)
+	// The encoded credentials reveal a password resolved from a secret
+	if secrets.IsSecret(b.password) {
+		secrets.Register(strings.TrimPrefix(req.Header.Get("Authorization"), "Basic "))
+	}
+	return nil
+}
+
+// bearerAuth sets an "Authorization: Bearer" header
+type bearerAuth struct {
+	token string
+}
+
+func newBearerAuth(auth config.Auth) (Authenticator, error) {
+	if auth.Token == "" {
+		return nil, errors.New("bearer auth requires a token")
+	}
+	return bearerAuth{token: auth.Token}, nil
+}
+
+func (b bearerAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+// sendWithAuth sends a request through the authenticator built from auth
+// and returns the Authorization header received by the test server
+func sendWithAuth(t *testing.T, auth config.Auth) string {
+	t.Helper()
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	authenticator, err := newAuthenticator(auth)
+	if err != nil {
+		t.Fatalf("unexpected error building authenticator: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	if err := authenticator.Authenticate(req); err != nil {
+		t.Fatalf("unexpected error authenticating request: %v", err)
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	return received
+}
+
+func TestNoAuth(t *testing.T) {
+	for _, authType := range []string{"", "none"} {
+		if got := sendWithAuth(t, config.Auth{Type: authType}); got != "" {
+			t.Errorf("auth type %q: expected no Authorization header, got %q", authType, got)
+		}
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	got := sendWithAuth(t, config.Auth{Type: "basic", Username: "hippo", Password: "rules"})
+	if want := "Basic aGlwcG86cnVsZXM="; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if _, err := newAuthenticator(config.Auth{Type: "basic"}); err == nil {
+		t.Errorf("expected error for basic auth without username")
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	got := sendWithAuth(t, config.Auth{Type: "Bearer", Token: "tokenB"})
+	if want := "Bearer tokenB"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if _, err := newAuthenticator(config.Auth{Type: "bearer"}); err == nil {
+		t.Errorf("expected error for bearer auth without token")
+	}
+}
+
+func TestUnknownAuthType(t *testing.T) {
+	if _, err := newAuthenticator(config.Auth{Type: "digest"}); err == nil {
+		t.Errorf("expected error for unknown auth type")
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pbidwell/hippocurl/internal/secrets"
+	"github.com/pbidwell/hippocurl/utils"
+)
+
+// renderCurl renders a request as a shell-quoted curl command. Values of
+// secret references are always masked; with redact set, secret headers and
+// query parameters are replaced by a placeholder too.
+func renderCurl(req *http.Request, body string, redact bool) string {
+	parts := []string{"curl"}
+
+	switch {
+	case req.Method == http.MethodHead:
+		parts = append(parts, "-I")
+	case req.Method != http.MethodGet || body != "":
+		parts = append(parts, "-X "+req.Method)
+	}
+
+	reqURL := *req.URL
+	if redact {
+		reqURL.RawQuery = redactQuery(reqURL.Query())
+		if reqURL.User != nil {
+			reqURL.User = url.UserPassword(reqURL.User.Username(), utils.Redacted)
+		}
+	}
+	parts = append(parts, shellQuote(secrets.Mask(reqURL.String())))
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			if redact && utils.IsSensitiveKey(name) {
+				value = redactHeaderValue(value)
+			}
+			parts = append(parts, "-H "+shellQuote(name+": "+secrets.Mask(value)))
+		}
+	}
+
+	if body != "" {
+		parts = append(parts, "--data-raw "+shellQuote(secrets.Mask(body)))
+	}
+
+	return strings.Join(parts, " \\\n  ")
+}
+
+// redactHeaderValue hides a header value, keeping the auth scheme of
+// Authorization headers so the command still documents how to authenticate
+func redactHeaderValue(value string) string {
+	if scheme, _, ok := strings.Cut(value, " "); ok && (strings.EqualFold(scheme, "bearer") || strings.EqualFold(scheme, "basic")) {
+		return scheme + " " + utils.Redacted
+	}
+	return utils.Redacted
+}
+
+func redactQuery(query url.Values) string {
+	for key := range query {
+		if utils.IsSensitiveKey(key) {
+			for i := range query[key] {
+				query[key][i] = utils.Redacted
+			}
+		}
+	}
+	return query.Encode()
+}
+
+// shellQuote quotes s for POSIX shells, leaving simple words unquoted
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+%", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+func TestRenderCurl(t *testing.T) {
+	prepared := &PreparedRequest{
+		Method:  "POST",
+		URL:     "https://api.example.com/users?api_key=k1&page=2",
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Auth:    config.Auth{Type: "bearer", Token: "t0ken"},
+		Body:    `{"name": "it's hippo"}`,
+	}
+	req, err := prepared.newHTTPRequest(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"curl",
+		"-X POST",
+		"'https://api.example.com/users?api_key=k1&page=2'",
+		"-H 'Authorization: Bearer t0ken'",
+		"-H 'Content-Type: application/json'",
+		`--data-raw '{"name": "it'\''s hippo"}'`,
+	}, " \\\n  ")
+	if got := renderCurl(req, prepared.Body, false); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	redacted := renderCurl(req, prepared.Body, true)
+	for _, secret := range []string{"t0ken", "k1"} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("expected %q to be redacted:\n%s", secret, redacted)
+		}
+	}
+	if !strings.Contains(redacted, "'Authorization: Bearer REDACTED'") || !strings.Contains(redacted, "page=2") {
+		t.Errorf("unexpected redacted command:\n%s", redacted)
+	}
+}
+
+func TestRenderCurlGet(t *testing.T) {
+	prepared := &PreparedRequest{Method: "GET", URL: "https://example.com/ip"}
+	req, _ := prepared.newHTTPRequest(context.Background())
+	if got := renderCurl(req, "", false); got != "curl \\\n  https://example.com/ip" {
+		t.Errorf("unexpected command: %q", got)
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/jsondiff"
+	"github.com/pbidwell/hippocurl/internal/jsonpath"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+	"github.com/pbidwell/hippocurl/internal/vars"
+	"github.com/pbidwell/hippocurl/modules"
+	"github.com/pbidwell/hippocurl/utils"
+
+	"github.com/briandowns/spinner"
+	"github.com/rodaine/table"
+)
+
+// DiffModule implements the HippoModule interface
+type DiffModule struct {
+	Overrides Overrides
+	Ignore    []string // JSONPaths skipped in addition to the route's ignore list
+	Headers   []string // Response headers compared besides the status and body
+}
+
+// defaultDiffHeaders are the headers compared when none are selected
+var defaultDiffHeaders = []string{"Content-Type"}
+
+// diffValueWidth is the width values are shortened to in the differences table
+const diffValueWidth = 60
+
+func (d DiffModule) Name() string {
+	return "diff"
+}
+
+func (d DiffModule) Description() string {
+	return "Sends a route to two environments and compares the responses."
+}
+
+func (d DiffModule) Use() string {
+	return "api diff <serviceName> <routeName> <environmentA> <environmentB>"
+}
+
+func (d DiffModule) Logo() string {
+	return "🔀"
+}
+
+func (d DiffModule) Execute(ctx context.Context, app *config.App, args []string) error {
+	utils.Print("api diff", utils.ModuleTitle)
+	Setup(app)
+
+	if len(args) != 4 {
+		return modules.Usagef("usage: hc %s", d.Use())
+	}
+	service := app.APIConfig.GetServiceByName(args[0])
+	if service == nil {
+		return modules.Usagef("unknown service %q", args[0])
+	}
+	route := service.GetRouteByName(args[1])
+	if route == nil {
+		return modules.Usagef("unknown route %q for service %s", args[1], service.Name)
+	}
+	var envs [2]*config.Environment
+	for i, name := range args[2:] {
+		if envs[i] = service.GetEnvironmentByName(name); envs[i] == nil {
+			return modules.Usagef("unknown environment %q for service %s", name, service.Name)
+		}
+	}
+
+	ignore, err := parseIgnorePaths(append(append([]string{}, route.Ignore...), d.Ignore...))
+	if err != nil {
+		return modules.Usagef("%v", err)
+	}
+	headers := d.Headers
+	if len(headers) == 0 {
+		headers = defaultDiffHeaders
+	}
+
+	var prepared [2]*PreparedRequest
+	for i, env := range envs {
+		store, err := vars.LoadStore(filepath.Join(app.ConfigDir, vars.StoreFileName), service.Name, env.Name)
+		if err != nil {
+			return fmt.Errorf("loading variable store: %w", err)
+		}
+		if prepared[i], err = PrepareRequest(service, route, env, d.Overrides, store.Values); err != nil {
+			return fmt.Errorf("preparing request for %s: %w", env.Name, err)
+		}
+	}
+
+	spinner := spinner.New(spinner.CharSets[35], 100*time.Millisecond)
+	spinner.Start()
+	var responses [2]*Response
+	var sent [2]*http.Request
+	var errs [2]error
+	var wg sync.WaitGroup
+	for i := range prepared {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = prepared[i].send(ctx, func(attempt Attempt) {
+				sent[i] = attempt.Request
+			})
+		}(i)
+	}
+	wg.Wait()
+	spinner.Stop()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	// Both exchanges are recorded once sent, as the history isn't safe for
+	// concurrent appends
+	for i := range prepared {
+		if sent[i] != nil {
+			recordHistory(newHistoryEntry(service, route, envs[i], d.Overrides), sent[i], prepared[i].Body, responses[i], errs[i])
+		}
+	}
+	for i, err := range errs {
+		if sent[i] == nil {
+			return fmt.Errorf("creating request for %s: %w", envs[i].Name, err)
+		}
+		if err != nil {
+			return modules.Exit(modules.ExitTransport, fmt.Errorf("sending request to %s: %w", envs[i].Name, err))
+		}
+	}
+
+	diffs, ignored := compareResponses(responses[0], responses[1], headers, ignore)
+	printComparison(envs[0].Name, envs[1].Name, responses, headers)
+	alogger.Printf("Compared %s/%s in %s and %s: %d differences, %d ignored\n", service.Name, route.Name, envs[0].Name, envs[1].Name, len(diffs), ignored)
+
+	utils.Print("Differences", utils.Header1)
+	if len(diffs) > 0 {
+		tbl := table.New("[Path]", "[Kind]", "["+envs[0].Name+"]", "["+envs[1].Name+"]")
+		for _, diff := range diffs {
+			tbl.AddRow(diff.Path, diff.Kind, formatDiffValue(diff.Left, diff.Kind == jsondiff.Added), formatDiffValue(diff.Right, diff.Kind == jsondiff.Removed))
+		}
+		tbl.Print()
+	}
+	utils.Print(fmt.Sprintf("%d differences, %d ignored", len(diffs), ignored), utils.Header2)
+
+	if len(diffs) > 0 {
+		return modules.Exit(modules.ExitAssertion, nil)
+	}
+	return nil
+}
+
+// parseIgnorePaths parses the JSONPaths of an ignore list
+func parseIgnorePaths(exprs []string) ([]*jsonpath.Path, error) {
+	paths := make([]*jsonpath.Path, 0, len(exprs))
+	for _, expr := range exprs {
+		path, err := jsonpath.Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("ignore: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// compareResponses returns the differences in status, the given headers and
+// body between two responses, and the number of ignored body differences.
+// Bodies that aren't both JSON are compared as text.
+func compareResponses(left, right *Response, headers []string, ignore []*jsonpath.Path) ([]jsondiff.Difference, int) {
+	var diffs []jsondiff.Difference
+	if left.StatusCode != right.StatusCode {
+		diffs = append(diffs, jsondiff.Difference{Path: "status", Kind: jsondiff.Changed, Left: left.StatusCode, Right: right.StatusCode})
+	}
+	for _, name := range headers {
+		l, r := left.Headers.Values(name), right.Headers.Values(name)
+		if strings.Join(l, ", ") != strings.Join(r, ", ") {
+			diffs = append(diffs, headerDifference(http.CanonicalHeaderKey(name), l, r))
+		}
+	}
+
+	leftDoc, leftErr := jsonpath.Decode(left.Body)
+	rightDoc, rightErr := jsonpath.Decode(right.Body)
+	if leftErr != nil || rightErr != nil {
+		if string(left.Body) != string(right.Body) {
+			diffs = append(diffs, jsondiff.Difference{Path: "body", Kind: jsondiff.Changed, Left: string(left.Body), Right: string(right.Body)})
+		}
+		return diffs, 0
+	}
+
+	result := jsondiff.Compare(leftDoc, rightDoc, ignore)
+	for _, diff := range result.Differences {
+		diff.Path = "body" + strings.TrimPrefix(diff.Path, "$")
+		diffs = append(diffs, diff)
+	}
+	return diffs, result.Ignored
+}
+
+func headerDifference(name string, left, right []string) jsondiff.Difference {
+	diff := jsondiff.Difference{Path: "header " + name, Kind: jsondiff.Changed}
+	switch {
+	case len(left) == 0:
+		diff.Kind = jsondiff.Added
+	case len(right) == 0:
+		diff.Kind = jsondiff.Removed
+	}
+	if len(left) > 0 {
+		diff.Left = strings.Join(left, ", ")
+	}
+	if len(right) > 0 {
+		diff.Right = strings.Join(right, ", ")
+	}
+	return diff
+}
+
+// printComparison prints the status, latency and compared headers of both
+// responses side by side
+func printComparison(leftName, rightName string, responses [2]*Response, headers []string) {
+	utils.Print("Responses", utils.Header1)
+	tbl := table.New("", "["+leftName+"]", "["+rightName+"]")
+	tbl.AddRow("Status", responses[0].Status, responses[1].Status)
+	tbl.AddRow("Latency", responses[0].Latency.Round(time.Millisecond), responses[1].Latency.Round(time.Millisecond))
+	for _, name := range headers {
+		tbl.AddRow(http.CanonicalHeaderKey(name), secrets.Mask(responses[0].Headers.Get(name)), secrets.Mask(responses[1].Headers.Get(name)))
+	}
+	tbl.Print()
+}
+
+// formatDiffValue renders a value for the differences table, shortened to
+// diffValueWidth. missing marks the side the value doesn't exist on.
+func formatDiffValue(value any, missing bool) string {
+	if missing {
+		return "-"
+	}
+	text := ""
+	switch value := value.(type) {
+	case string:
+		text = fmt.Sprintf("%q", value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			text = fmt.Sprintf("%v", value)
+		} else {
+			text = string(data)
+		}
+	}
+	text = secrets.Mask(text)
+	if runes := []rune(text); len(runes) > diffValueWidth {
+		text = string(runes[:diffValueWidth-3]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/secrets"
+)
+
+func TestCompareResponses(t *testing.T) {
+	left := &Response{
+		StatusCode: 200,
+		Headers:    http.Header{"Content-Type": {"application/json"}, "Etag": {"1"}},
+		Body:       []byte(`{"id":7,"name":"hippo","meta":{"requested_at":"10:00"}}`),
+	}
+	right := &Response{
+		StatusCode: 201,
+		Headers:    http.Header{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"id":8,"name":"hippo","meta":{"requested_at":"10:01"}}`),
+	}
+
+	ignore, err := parseIgnorePaths([]string{"$.meta"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	diffs, ignored := compareResponses(left, right, []string{"content-type", "etag"}, ignore)
+
+	want := []string{"status", "header Etag", "body.id"}
+	if len(diffs) != len(want) {
+		t.Fatalf("expected differences %v, got %+v", want, diffs)
+	}
+	for i, diff := range diffs {
+		if diff.Path != want[i] {
+			t.Errorf("difference %d: expected %s, got %s", i, want[i], diff.Path)
+		}
+	}
+	if diffs[1].Kind != "removed" {
+		t.Errorf("expected the ETag header to be removed, got %s", diffs[1].Kind)
+	}
+	if ignored != 1 {
+		t.Errorf("expected 1 ignored difference, got %d", ignored)
+	}
+}
+
+func TestCompareResponsesText(t *testing.T) {
+	left := &Response{StatusCode: 200, Body: []byte("ok")}
+	right := &Response{StatusCode: 200, Body: []byte("ok\n")}
+	if diffs, _ := compareResponses(left, right, nil, nil); len(diffs) != 1 || diffs[0].Path != "body" {
+		t.Errorf("expected the text bodies to differ, got %+v", diffs)
+	}
+	if diffs, _ := compareResponses(left, left, nil, nil); len(diffs) != 0 {
+		t.Errorf("expected no differences, got %+v", diffs)
+	}
+}
+
+func TestFormatDiffValueMasksSecrets(t *testing.T) {
+	secrets.Register("diff-secret-token")
+	if got := formatDiffValue("Bearer diff-secret-token", false); strings.Contains(got, "diff-secret-token") {
+		t.Errorf("expected the resolved secret to be masked, got %s", got)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/jsonpath"
+	"github.com/pbidwell/hippocurl/internal/vars"
+	"github.com/pbidwell/hippocurl/utils"
+)
+
+// extractVariables applies a route's extract rules to the response and
+// prints the captured values
+func extractVariables(rules []config.Extraction, resp *Response, store *vars.Store) {
+	utils.Print("Extracted Variables", utils.Header2)
+
+	values, errs := ApplyExtractions(rules, resp, store)
+	for _, rule := range rules {
+		if value, ok := values[rule.Name]; ok {
+			utils.PrintFieldValuePair(rule.Name, value)
+		}
+	}
+	for _, err := range errs {
+		alogger.Printf("Error extracting variable: %v\n", err)
+		utils.Print(fmt.Sprintf("Error extracting variable: %v", err), utils.NormalText)
+	}
+}
+
+// ApplyExtractions evaluates extract rules against a response and saves the
+// captured values to the variable store
+func ApplyExtractions(rules []config.Extraction, resp *Response, store *vars.Store) (map[string]string, []error) {
+	values, errs := extractValues(rules, resp)
+	if len(values) == 0 {
+		return values, errs
+	}
+
+	for name, value := range values {
+		store.Set(name, value)
+	}
+	if err := store.Save(); err != nil {
+		errs = append(errs, err)
+	}
+	return values, errs
+}
+
+// extractValues evaluates extract rules against a response, returning the
+// captured values by variable name and an error for each rule that failed
+func extractValues(rules []config.Extraction, resp *Response) (map[string]string, []error) {
+	values := make(map[string]string)
+	var errs []error
+
+	ex := &extractor{resp: resp}
+	for _, rule := range rules {
+		value, err := ex.extract(rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", rule.Name, err))
+			continue
+		}
+		values[rule.Name] = value
+	}
+
+	return values, errs
+}
+
+// extractor evaluates extract rules, decoding a JSON body at most once
+type extractor struct {
+	resp    *Response
+	doc     any
+	docErr  error
+	decoded bool
+}
+
+func (e *extractor) extract(rule config.Extraction) (string, error) {
+	switch {
+	case rule.Name == "":
+		return "", errors.New("extract rule without a name")
+	case rule.JSONPath != "":
+		doc, err := e.json()
+		if err != nil {
+			return "", err
+		}
+		match, err := jsonpath.Lookup(doc, rule.JSONPath)
+		if err != nil {
+			return "", err
+		}
+		return jsonpath.Format(match), nil
+	case rule.Header != "":
+		if _, ok := e.resp.Headers[http.CanonicalHeaderKey(rule.Header)]; !ok {
+			return "", fmt.Errorf("header %s not found", rule.Header)
+		}
+		return e.resp.Headers.Get(rule.Header), nil
+	case rule.Regex != "":
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return "", fmt.Errorf("invalid regex %q: %w", rule.Regex, err)
+		}
+		match := re.FindSubmatch(e.resp.Body)
+		if match == nil {
+			return "", fmt.Errorf("regex %q did not match", rule.Regex)
+		}
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+		return string(match[0]), nil
+	default:
+		return "", errors.New("no json_path, header or regex given")
+	}
+}
+
+func (e *extractor) json() (any, error) {
+	if !e.decoded {
+		e.doc, e.docErr = jsonpath.Decode(e.resp.Body)
+		e.decoded = true
+	}
+	return e.doc, e.docErr
+}
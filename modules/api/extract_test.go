@@ -0,0 +1,49 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+func TestExtractValues(t *testing.T) {
+	resp := &Response{
+		StatusCode: http.StatusOK,
+		Headers:    http.Header{"X-Session-Id": {"sess-42"}},
+		Body:       []byte(`{"user": {"id": 7, "name": "hippo"}, "note": "csrf=tok123;"}`),
+	}
+	rules := []config.Extraction{
+		{Name: "userId", JSONPath: "$.user.id"},
+		{Name: "session", Header: "x-session-id"},
+		{Name: "csrf", Regex: `csrf=(\w+)`},
+		{Name: "missing", JSONPath: "$.user.email"},
+		{Name: "nothing"},
+	}
+
+	values, errs := extractValues(rules, resp)
+
+	want := map[string]string{"userId": "7", "session": "sess-42", "csrf": "tok123"}
+	for name, value := range want {
+		if values[name] != value {
+			t.Errorf("%s: expected %q, got %q", name, value, values[name])
+		}
+	}
+	if len(values) != len(want) {
+		t.Errorf("unexpected extracted values: %v", values)
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+}
+
+func TestExtractValuesFromNonJSONBody(t *testing.T) {
+	resp := &Response{Body: []byte("<html></html>")}
+	_, errs := extractValues([]config.Extraction{{Name: "id", JSONPath: "$.id"}}, resp)
+	if len(errs) != 1 {
+		t.Errorf("expected an error for a non-JSON body, got %v", errs)
+	}
+}
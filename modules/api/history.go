@@ -0,0 +1,147 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/history"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+	"github.com/pbidwell/hippocurl/utils"
+)
+
+// historyStore records the calls made by the api module, set by Setup
+var historyStore *history.Store
+
+// newHistoryEntry starts a history entry for a call of the api module
+func newHistoryEntry(service *config.Service, route *config.Route, env *config.Environment, overrides Overrides) *history.Entry {
+	entry := &history.Entry{
+		Service:     service.Name,
+		Route:       route.Name,
+		Environment: env.Name,
+		Query:       overrides.Query,
+	}
+	// Variables that look like secrets aren't stored; re-running the entry
+	// resolves them from the config again
+	for name, value := range overrides.Variables {
+		if utils.IsSensitiveKey(name) {
+			continue
+		}
+		if entry.Variables == nil {
+			entry.Variables = make(map[string]string)
+		}
+		entry.Variables[name] = value
+	}
+	return entry
+}
+
+// recordHistory completes entry with the exchange and adds it to the
+// history, redacting secrets. Failures are logged without failing the call.
+func recordHistory(entry *history.Entry, req *http.Request, body string, resp *Response, sendErr error) {
+	if historyStore == nil || entry == nil {
+		return
+	}
+
+	out := newOutputRequest(req, body, true)
+	entry.Time = time.Now()
+	entry.Request = history.Request{
+		Method:  out.Method,
+		URL:     out.URL,
+		Headers: out.Headers,
+		Body:    history.NewBody(redactBody([]byte(out.Body), req.Header.Get("Content-Type")), historyStore.MaxBodySize),
+	}
+	if sendErr != nil {
+		entry.Error = sendErr.Error()
+	}
+	if resp != nil {
+		headers := secrets.MaskHeader(resp.Headers)
+		for name, values := range headers {
+			if utils.IsSensitiveKey(name) {
+				for i := range values {
+					values[i] = utils.Redacted
+				}
+			}
+		}
+		entry.Response = &history.Response{
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+			Proto:      resp.Proto,
+			Headers:    headers,
+			Body:       history.NewBody(redactBody([]byte(secrets.Mask(string(resp.Body))), resp.Headers.Get("Content-Type")), historyStore.MaxBodySize),
+		}
+		timings := newOutputTimings(resp)
+		entry.Timings = &history.Timings{
+			DNSMS:      timings.DNSMS,
+			ConnectMS:  timings.ConnectMS,
+			TLSMS:      timings.TLSMS,
+			WaitMS:     timings.WaitMS,
+			TransferMS: timings.TransferMS,
+			TotalMS:    timings.TotalMS,
+			Reused:     timings.Reused,
+			Protocol:   timings.Protocol,
+			TLSVersion: timings.TLSVersion,
+		}
+	}
+
+	if err := historyStore.Append(entry); err != nil {
+		alogger.Printf("Error recording history: %v\n", err)
+		return
+	}
+	alogger.Printf("Recorded %s %s/%s/%s as history entry %d\n", entry.Request.Method, entry.Service, entry.Route, entry.Environment, entry.ID)
+}
+
+// redactBody hides the values of secret-looking fields in JSON and form
+// bodies, such as passwords in login requests and tokens in their responses
+func redactBody(body []byte, contentType string) []byte {
+	switch {
+	case strings.Contains(contentType, "json"):
+		var value any
+		if json.Unmarshal(body, &value) != nil || !redactJSON(value) {
+			return body
+		}
+		redacted, err := json.Marshal(value)
+		if err != nil {
+			return body
+		}
+		return redacted
+	case strings.Contains(contentType, "x-www-form-urlencoded"):
+		query, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body
+		}
+		for key := range query {
+			if utils.IsSensitiveKey(key) {
+				return []byte(redactQuery(query))
+			}
+		}
+	}
+	return body
+}
+
+// redactJSON replaces secret-looking fields of a decoded JSON value in
+// place, reporting whether it changed anything
+func redactJSON(value any) bool {
+	changed := false
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if _, isString := field.(string); isString && utils.IsSensitiveKey(key) {
+				value[key] = utils.Redacted
+				changed = true
+				continue
+			}
+			changed = redactJSON(field) || changed
+		}
+	case []any:
+		for _, item := range value {
+			changed = redactJSON(item) || changed
+		}
+	}
+	return changed
+}
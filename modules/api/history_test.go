@@ -0,0 +1,25 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	cases := []struct {
+		body, contentType, want string
+	}{
+		{`{"user":"hippo","password":"s3cret","nested":[{"access_token":"abc"}]}`, "application/json",
+			`{"nested":[{"access_token":"REDACTED"}],"password":"REDACTED","user":"hippo"}`},
+		{`{"user":"hippo"}`, "application/json", `{"user":"hippo"}`},
+		{"client_id=hc&client_secret=s3cret", "application/x-www-form-urlencoded", "client_id=hc&client_secret=REDACTED"},
+		{"password=s3cret", "text/plain", "password=s3cret"},
+	}
+	for _, c := range cases {
+		if got := string(redactBody([]byte(c.body), c.contentType)); got != c.want {
+			t.Errorf("redactBody(%q): expected %q, got %q", c.body, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+)
+
+const (
+	grantClientCredentials = "client_credentials"
+	grantRefreshToken      = "refresh_token"
+
+	tokenCacheFileName = "oauth2_tokens.json"
+
+	// tokens this close to expiry are treated as already expired
+	tokenExpirySkew = 30 * time.Second
+)
+
+// tokenCachePath is the file OAuth2 tokens are cached in between runs.
+// An empty path disables caching.
+var tokenCachePath string
+
+// tokenCacheMu serializes access to the token cache file
+var tokenCacheMu sync.Mutex
+
+func init() {
+	authenticators["oauth2_client_credentials"] = newOAuth2Auth(grantClientCredentials)
+	authenticators["oauth2_refresh_token"] = newOAuth2Auth(grantRefreshToken)
+}
+
+// oauth2Auth fetches an access token from a token endpoint and sets it
+// as the request's Authorization header. Fetching the token is bound to the
+// request's context.
+type oauth2Auth struct {
+	grantType string
+	auth      config.Auth
+	client    *http.Client
+}
+
+// cachedToken is a token endpoint response as stored in the token cache
+type cachedToken struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// tokenResponse is the token endpoint's JSON response (RFC 6749, section 5.1)
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Error        string `json:"error"`
+	ErrorDesc    string `json:"error_description"`
+}
+
+func newOAuth2Auth(grantType string) authenticatorFactory {
+	return func(auth config.Auth) (Authenticator, error) {
+		if auth.TokenURL == "" {
+			return nil, fmt.Errorf("%s auth requires a token_url", auth.Type)
+		}
+		if grantType == grantClientCredentials && auth.ClientID == "" {
+			return nil, fmt.Errorf("%s auth requires a client_id", auth.Type)
+		}
+		if grantType == grantRefreshToken && auth.RefreshToken == "" {
+			return nil, fmt.Errorf("%s auth requires a refresh_token", auth.Type)
+		}
+		return &oauth2Auth{
+			grantType: grantType,
+			auth:      auth,
+			client:    &http.Client{},
+		}, nil
+	}
+}
+
+func (o *oauth2Auth) Authenticate(req *http.Request) error {
+	token, err := o.token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", token.authorizationHeader())
+	// Fetched tokens are as sensitive as the credentials they came from
+	secrets.Register(token.AccessToken)
+	secrets.Register(token.RefreshToken)
+	return nil
+}
+
+// token returns a valid access token, using the cache when possible and
+// refreshing expired tokens transparently
+func (o *oauth2Auth) token(ctx context.Context) (*cachedToken, error) {
+	tokenCacheMu.Lock()
+	defer tokenCacheMu.Unlock()
+
+	key := o.cacheKey()
+	cache := loadTokenCache()
+
+	cached, ok := cache[key]
+	if ok && cached.valid() {
+		return cached, nil
+	}
+
+	var token *cachedToken
+	var err error
+	refreshToken := "" // The refresh token the new token was issued for
+	if o.grantType == grantRefreshToken {
+		refreshToken = o.auth.RefreshToken
+	}
+	if ok && cached.RefreshToken != "" {
+		token, err = o.fetch(ctx, grantRefreshToken, cached.RefreshToken)
+		if err == nil {
+			refreshToken = cached.RefreshToken
+		} else if ctx.Err() == nil && (o.grantType == grantClientCredentials || refreshToken != cached.RefreshToken) {
+			// The cached refresh token may have been revoked; fall back to
+			// the configured grant
+			token, err = o.fetch(ctx, o.grantType, refreshToken)
+		}
+	} else {
+		token, err = o.fetch(ctx, o.grantType, refreshToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep using the previous refresh token unless the server rotated it
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+
+	cache[key] = token
+	saveTokenCache(cache)
+	return token, nil
+}
+
+// fetch requests a new token from the token endpoint
+func (o *oauth2Auth) fetch(ctx context.Context, grantType, refreshToken string) (*cachedToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", grantType)
+	if grantType == grantRefreshToken {
+		form.Set("refresh_token", refreshToken)
+	}
+	if len(o.auth.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.auth.Scopes, " "))
+	}
+	if o.auth.Audience != "" {
+		form.Set("audience", o.auth.Audience)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.auth.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if o.auth.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(o.auth.ClientID), url.QueryEscape(o.auth.ClientSecret))
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading token response: %w", err)
+	}
+
+	var tr tokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, fmt.Errorf("token endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if tr.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned %s: %s %s", resp.Status, tr.Error, tr.ErrorDesc)
+	}
+	if resp.StatusCode != http.StatusOK || tr.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned %s without an access token", resp.Status)
+	}
+
+	token := &cachedToken{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+	}
+	if tr.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	alogger.Printf("Fetched OAuth2 token from %s using %s grant", o.auth.TokenURL, grantType)
+	return token, nil
+}
+
+// cacheKey identifies the credentials a token was issued for
+func (o *oauth2Auth) cacheKey() string {
+	parts := []string{
+		o.grantType,
+		o.auth.TokenURL,
+		o.auth.ClientID,
+		o.auth.RefreshToken,
+		strings.Join(o.auth.Scopes, " "),
+		o.auth.Audience,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// valid reports whether the token can still be used. Tokens issued
+// without an expiry are never reused.
+func (t *cachedToken) valid() bool {
+	if t.AccessToken == "" || t.Expiry.IsZero() {
+		return false
+	}
+	return time.Now().Add(tokenExpirySkew).Before(t.Expiry)
+}
+
+func (t *cachedToken) authorizationHeader() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// loadTokenCache reads the token cache, returning an empty cache if it
+// doesn't exist or can't be parsed
+func loadTokenCache() map[string]*cachedToken {
+	cache := make(map[string]*cachedToken)
+	if tokenCachePath == "" {
+		return cache
+	}
+
+	data, err := os.ReadFile(tokenCachePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			alogger.Printf("Error reading token cache: %v", err)
+		}
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		alogger.Printf("Error parsing token cache, ignoring it: %v", err)
+	}
+	return cache
+}
+
+// saveTokenCache writes the token cache, readable only by the current user
+func saveTokenCache(cache map[string]*cachedToken) {
+	if tokenCachePath == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err == nil {
+		err = os.WriteFile(tokenCachePath, data, 0600)
+	}
+	if err != nil {
+		alogger.Printf("Error writing token cache: %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+)
+
+// tokenServer is a fake OAuth2 token endpoint handing out numbered tokens
+type tokenServer struct {
+	*httptest.Server
+	requests  []http.Request
+	forms     []map[string]string
+	expiresIn int
+	revoked   string // Refresh token rejected as invalid_grant
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	t.Helper()
+
+	ts := &tokenServer{expiresIn: expiresIn}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		form := map[string]string{}
+		for key := range r.PostForm {
+			form[key] = r.PostForm.Get(key)
+		}
+		ts.requests = append(ts.requests, *r)
+		ts.forms = append(ts.forms, form)
+
+		w.Header().Set("Content-Type", "application/json")
+		if ts.revoked != "" && form["refresh_token"] == ts.revoked {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		n := len(ts.forms)
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  fmt.Sprintf("access-%d", n),
+			"token_type":    "bearer",
+			"refresh_token": fmt.Sprintf("refresh-%d", n),
+			"expires_in":    ts.expiresIn,
+		})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func setupTokenCache(t *testing.T) {
+	t.Helper()
+	alogger = log.New(io.Discard, "", 0)
+	tokenCachePath = filepath.Join(t.TempDir(), tokenCacheFileName)
+	t.Cleanup(func() { tokenCachePath = "" })
+}
+
+func authorize(t *testing.T, auth config.Auth) string {
+	t.Helper()
+
+	authenticator, err := newAuthenticator(auth)
+	if err != nil {
+		t.Fatalf("unexpected error building authenticator: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err := authenticator.Authenticate(req); err != nil {
+		t.Fatalf("unexpected error authenticating request: %v", err)
+	}
+	return req.Header.Get("Authorization")
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	setupTokenCache(t)
+	ts := newTokenServer(t, 3600)
+
+	auth := config.Auth{
+		Type:         "oauth2_client_credentials",
+		TokenURL:     ts.URL,
+		ClientID:     "hippo",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+		Audience:     "https://api.example.com",
+	}
+
+	if got := authorize(t, auth); got != "Bearer access-1" {
+		t.Errorf("expected Bearer access-1, got %q", got)
+	}
+	if len(ts.forms) != 1 {
+		t.Fatalf("expected 1 token request, got %d", len(ts.forms))
+	}
+
+	form := ts.forms[0]
+	if form["grant_type"] != "client_credentials" || form["scope"] != "read write" || form["audience"] != "https://api.example.com" {
+		t.Errorf("unexpected token request form: %v", form)
+	}
+	if user, pass, ok := ts.requests[0].BasicAuth(); !ok || user != "hippo" || pass != "secret" {
+		t.Errorf("expected client credentials in basic auth, got %q/%q", user, pass)
+	}
+
+	// A second run must reuse the cached token
+	if got := authorize(t, auth); got != "Bearer access-1" {
+		t.Errorf("expected cached Bearer access-1, got %q", got)
+	}
+	if len(ts.forms) != 1 {
+		t.Errorf("expected cached token to be reused, got %d token requests", len(ts.forms))
+	}
+}
+
+func TestOAuth2RefreshesExpiredToken(t *testing.T) {
+	setupTokenCache(t)
+	// Tokens expiring within the skew are immediately stale
+	ts := newTokenServer(t, int(tokenExpirySkew/time.Second)-1)
+
+	auth := config.Auth{Type: "oauth2_client_credentials", TokenURL: ts.URL, ClientID: "hippo"}
+
+	authorize(t, auth)
+	if got := authorize(t, auth); got != "Bearer access-2" {
+		t.Errorf("expected refreshed Bearer access-2, got %q", got)
+	}
+	if len(ts.forms) != 2 {
+		t.Fatalf("expected 2 token requests, got %d", len(ts.forms))
+	}
+	if form := ts.forms[1]; form["grant_type"] != "refresh_token" || form["refresh_token"] != "refresh-1" {
+		t.Errorf("expected refresh using refresh-1, got %v", form)
+	}
+}
+
+func TestOAuth2RefreshToken(t *testing.T) {
+	setupTokenCache(t)
+	ts := newTokenServer(t, 3600)
+
+	auth := config.Auth{Type: "oauth2_refresh_token", TokenURL: ts.URL, RefreshToken: "configured"}
+
+	if got := authorize(t, auth); got != "Bearer access-1" {
+		t.Errorf("expected Bearer access-1, got %q", got)
+	}
+	if form := ts.forms[0]; form["grant_type"] != "refresh_token" || form["refresh_token"] != "configured" {
+		t.Errorf("unexpected token request form: %v", form)
+	}
+	if masked := secrets.Mask("access-1 refresh-1"); strings.Contains(masked, "access-1") || strings.Contains(masked, "refresh-1") {
+		t.Errorf("expected fetched tokens to be masked, got %q", masked)
+	}
+}
+
+func TestOAuth2RefreshTokenFallsBackToConfigured(t *testing.T) {
+	setupTokenCache(t)
+	ts := newTokenServer(t, int(tokenExpirySkew/time.Second)-1)
+
+	auth := config.Auth{Type: "oauth2_refresh_token", TokenURL: ts.URL, RefreshToken: "configured"}
+
+	authorize(t, auth)
+	// The server rotated the refresh token to refresh-1, then revoked it
+	ts.revoked = "refresh-1"
+	if got := authorize(t, auth); got != "Bearer access-3" {
+		t.Errorf("expected Bearer access-3, got %q", got)
+	}
+	if len(ts.forms) != 3 {
+		t.Fatalf("expected 3 token requests, got %d", len(ts.forms))
+	}
+	if form := ts.forms[2]; form["grant_type"] != "refresh_token" || form["refresh_token"] != "configured" {
+		t.Errorf("expected a refresh using the configured token, got %v", form)
+	}
+}
+
+func TestOAuth2MissingSettings(t *testing.T) {
+	invalid := []config.Auth{
+		{Type: "oauth2_client_credentials", ClientID: "hippo"},
+		{Type: "oauth2_client_credentials", TokenURL: "http://example.com"},
+		{Type: "oauth2_refresh_token", TokenURL: "http://example.com"},
+	}
+	for _, auth := range invalid {
+		if _, err := newAuthenticator(auth); err == nil {
+			t.Errorf("expected error for incomplete auth %+v", auth)
+		}
+	}
+}
+
+func TestOAuth2TokenEndpointError(t *testing.T) {
+	setupTokenCache(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client"}`))
+	}))
+	defer server.Close()
+
+	authenticator, err := newAuthenticator(config.Auth{Type: "oauth2_client_credentials", TokenURL: server.URL, ClientID: "hippo"})
+	if err != nil {
+		t.Fatalf("unexpected error building authenticator: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err := authenticator.Authenticate(req); err == nil {
+		t.Errorf("expected token endpoint error to be reported")
+	}
+}
+
+func TestOAuth2TokenRequestUsesPolicyTimeout(t *testing.T) {
+	setupTokenCache(t)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	prepared := &PreparedRequest{
+		Method: "GET",
+		URL:    "http://example.com",
+		Auth:   config.Auth{Type: "oauth2_client_credentials", TokenURL: server.URL, ClientID: "hippo"},
+		Policy: config.Policy{Timeout: 50 * time.Millisecond},
+	}
+	start := time.Now()
+	if _, err := prepared.newHTTPRequest(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the token request to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the token request to stop after the policy's timeout, took %s", elapsed)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/secrets"
+	"github.com/pbidwell/hippocurl/utils"
+)
+
+// Output formats for scripted use of "hc api"
+const (
+	OutputJSON = "json" // A single JSON document describing the exchange
+	OutputRaw  = "raw"  // Status line, headers and body, like "curl -i"
+	OutputBody = "body" // The response body only
+)
+
+// OutputFormats lists the valid values of APIModule.Output
+var OutputFormats = []string{OutputJSON, OutputRaw, OutputBody}
+
+// outputDocument is the JSON written by "--output json"
+type outputDocument struct {
+	Request    outputRequest     `json:"request"`
+	Response   *outputResponse   `json:"response,omitempty"`
+	Timings    *outputTimings    `json:"timings,omitempty"`
+	Attempts   []outputAttempt   `json:"attempts,omitempty"` // Set when retries are enabled
+	Extracted  map[string]string `json:"extracted,omitempty"`
+	Assertions []AssertionResult `json:"assertions,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+type outputRequest struct {
+	Service     string      `json:"service"`
+	Route       string      `json:"route"`
+	Environment string      `json:"environment"`
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	Headers     http.Header `json:"headers"`
+	Body        string      `json:"body,omitempty"`
+}
+
+type outputResponse struct {
+	Status     string          `json:"status"`
+	StatusCode int             `json:"status_code"`
+	Proto      string          `json:"proto"`
+	Headers    http.Header     `json:"headers"`
+	Body       string          `json:"body"`
+	JSON       json.RawMessage `json:"json,omitempty"` // The body, when it is valid JSON
+}
+
+type outputTimings struct {
+	DNSMS      float64 `json:"dns_ms"`
+	ConnectMS  float64 `json:"connect_ms"`
+	TLSMS      float64 `json:"tls_ms"`
+	WaitMS     float64 `json:"wait_ms"` // Time to first byte after the request was written
+	TransferMS float64 `json:"transfer_ms"`
+	TotalMS    float64 `json:"total_ms"`
+	Reused     bool    `json:"reused"`
+	RemoteAddr string  `json:"remote_addr,omitempty"`
+	Protocol   string  `json:"protocol,omitempty"`
+	TLSVersion string  `json:"tls_version,omitempty"`
+}
+
+func newOutputTimings(resp *Response) *outputTimings {
+	out := &outputTimings{TotalMS: milliseconds(resp.Latency)}
+	if t := resp.Timings; t != nil {
+		out.DNSMS = milliseconds(t.DNS)
+		out.ConnectMS = milliseconds(t.Connect)
+		out.TLSMS = milliseconds(t.TLS)
+		out.WaitMS = milliseconds(t.Wait)
+		out.TransferMS = milliseconds(t.Transfer)
+		out.Reused = t.Reused
+		out.RemoteAddr = t.RemoteAddr
+		out.Protocol = t.Protocol
+		out.TLSVersion = t.TLSVersion
+	}
+	return out
+}
+
+type outputAttempt struct {
+	Attempt    int     `json:"attempt"`
+	StatusCode int     `json:"status_code,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	LatencyMS  float64 `json:"latency_ms,omitempty"`
+	RetryInMS  float64 `json:"retry_in_ms,omitempty"`
+}
+
+func newOutputAttempt(attempt Attempt) outputAttempt {
+	out := outputAttempt{Attempt: attempt.Number, RetryInMS: milliseconds(attempt.Delay)}
+	if attempt.Err != nil {
+		out.Error = attempt.Err.Error()
+	} else {
+		out.StatusCode = attempt.Response.StatusCode
+		out.LatencyMS = milliseconds(attempt.Response.Latency)
+	}
+	return out
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// newOutputRequest describes the sent request with the values of secret
+// references masked, hiding all secret-looking values if redact is set
+func newOutputRequest(req *http.Request, body string, redact bool) outputRequest {
+	out := outputRequest{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: req.Header.Clone(),
+		Body:    body,
+	}
+	if redact {
+		reqURL := *req.URL
+		reqURL.RawQuery = redactQuery(reqURL.Query())
+		if reqURL.User != nil {
+			reqURL.User = url.UserPassword(reqURL.User.Username(), utils.Redacted)
+		}
+		out.URL = reqURL.String()
+		for name, values := range out.Headers {
+			if utils.IsSensitiveKey(name) {
+				for i := range values {
+					values[i] = redactHeaderValue(values[i])
+				}
+			}
+		}
+	}
+	out.URL = secrets.Mask(out.URL)
+	out.Headers = secrets.MaskHeader(out.Headers)
+	out.Body = secrets.Mask(out.Body)
+	return out
+}
+
+// newOutputResponse describes the response with the values of secret
+// references masked, as servers may echo them back
+func newOutputResponse(resp *Response) *outputResponse {
+	body := secrets.Mask(string(resp.Body))
+	out := &outputResponse{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Proto:      resp.Proto,
+		Headers:    secrets.MaskHeader(resp.Headers),
+		Body:       body,
+	}
+	if json.Valid([]byte(body)) {
+		out.JSON = json.RawMessage(body)
+	}
+	return out
+}
+
+// writeOutput writes the exchange to w in the given format. Only the JSON
+// format describes failed requests.
+func writeOutput(w io.Writer, format string, doc *outputDocument, resp *Response) error {
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(doc)
+	case OutputRaw:
+		if resp == nil {
+			return nil
+		}
+		fmt.Fprintf(w, "%s %s\r\n", resp.Proto, resp.Status)
+		names := make([]string, 0, len(resp.Headers))
+		for name := range resp.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, value := range resp.Headers[name] {
+				fmt.Fprintf(w, "%s: %s\r\n", name, secrets.Mask(value))
+			}
+		}
+		fmt.Fprint(w, "\r\n")
+		_, err := io.WriteString(w, secrets.Mask(string(resp.Body)))
+		return err
+	case OutputBody:
+		if resp == nil {
+			return nil
+		}
+		_, err := io.WriteString(w, secrets.Mask(string(resp.Body)))
+		return err
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/secrets"
+)
+
+func TestWriteOutput(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://api.example.com/users?api_key=abc&page=2", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	resp := &Response{
+		Status:     "200 OK",
+		StatusCode: 200,
+		Proto:      "HTTP/1.1",
+		Headers:    http.Header{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"id":1,"name":"<hippo>"}`),
+	}
+	doc := &outputDocument{Request: newOutputRequest(req, "", true), Response: newOutputResponse(resp)}
+
+	var out bytes.Buffer
+	if err := writeOutput(&out, OutputJSON, doc, resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded struct {
+		Request struct {
+			URL     string
+			Headers map[string][]string
+		}
+		Response struct {
+			StatusCode int `json:"status_code"`
+			JSON       map[string]any
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	if strings.Contains(out.String(), "secret-token") || strings.Contains(decoded.Request.URL, "abc") {
+		t.Errorf("expected secrets to be redacted, got:\n%s", out.String())
+	}
+	if decoded.Response.StatusCode != 200 || decoded.Response.JSON["name"] != "<hippo>" {
+		t.Errorf("unexpected response: %+v", decoded.Response)
+	}
+	if req.Header.Get("Authorization") != "Bearer secret-token" {
+		t.Errorf("redaction modified the request headers")
+	}
+
+	out.Reset()
+	writeOutput(&out, OutputRaw, doc, resp)
+	if out.String() != "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n"+string(resp.Body) {
+		t.Errorf("unexpected raw output: %q", out.String())
+	}
+
+	out.Reset()
+	writeOutput(&out, OutputBody, doc, resp)
+	if out.String() != string(resp.Body) {
+		t.Errorf("unexpected body output: %q", out.String())
+	}
+}
+
+func TestWriteOutputMasksEchoedSecrets(t *testing.T) {
+	secrets.Register("output-secret-token")
+	resp := &Response{
+		Status:     "200 OK",
+		StatusCode: 200,
+		Proto:      "HTTP/1.1",
+		Headers:    http.Header{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"token":"output-secret-token"}`),
+	}
+	doc := &outputDocument{Response: newOutputResponse(resp)}
+
+	for _, format := range []string{OutputJSON, OutputRaw, OutputBody} {
+		var out bytes.Buffer
+		if err := writeOutput(&out, format, doc, resp); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if strings.Contains(out.String(), "output-secret-token") {
+			t.Errorf("%s: expected the echoed secret to be masked, got:\n%s", format, out.String())
+		}
+	}
+	if doc.Response.JSON == nil {
+		t.Errorf("expected the masked body to remain valid JSON")
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+	"github.com/pbidwell/hippocurl/internal/vars"
+)
+
+// Overrides holds request settings given on the command line, taking
+// precedence over the configuration file
+type Overrides struct {
+	Variables map[string]string
+	Query     map[string][]string
+	Policy    config.Policy // Timeout and retry flags
+}
+
+// PreparedRequest is a route resolved against an environment, with all
+// template variables expanded
+type PreparedRequest struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Auth    config.Auth
+	Body    string
+	Policy  config.Policy // Fully resolved timeout and retry settings
+}
+
+// Response is the part of an HTTP response hc inspects after a request
+type Response struct {
+	Status     string
+	StatusCode int
+	Proto      string // e.g. "HTTP/1.1"
+	Headers    http.Header
+	Body       []byte
+	Latency    time.Duration // Time from sending the request to reading the whole body
+	Attempts   int           // Number of attempts it took to receive this response
+	Timings    *Timings      // Phases of the request, nil if it wasn't traced
+}
+
+// secretResolver resolves secret references in config values. Setup points
+// it at the app's secret store.
+var secretResolver = secrets.NewResolver("")
+
+// PrepareRequest resolves the secret references of a route and environment,
+// then expands their variables. Variables passed on the command line take
+// precedence over values extracted from earlier responses, then environment
+// variables and finally service variables. References are only resolved in
+// config text, never in values substituted from the command line or from
+// responses, so those can't run commands or read files.
+func PrepareRequest(service *config.Service, route *config.Route, env *config.Environment, overrides Overrides, stored map[string]string) (*PreparedRequest, error) {
+	var resolveErr error
+	resolve := func(s string) string {
+		if resolveErr != nil {
+			return s
+		}
+		value, err := secretResolver.Resolve(s)
+		if err != nil {
+			resolveErr = err
+			return s
+		}
+		return value
+	}
+	resolver := vars.NewResolver(overrides.Variables, stored, env.Variables, service.Variables)
+	expander := resolver.Transform(2, resolve).Transform(3, resolve).Expander()
+	expand := func(s string) string {
+		return expander.Expand(resolve(s))
+	}
+
+	rawURL := expand(env.BaseURL) + expand(route.Path)
+	query := mergeQuery(env.Query, route.Query, overrides.Query)
+
+	req := &PreparedRequest{
+		Method:  route.Method,
+		Headers: make(map[string]string, len(env.Headers)+len(route.Headers)),
+		Auth:    env.Auth,
+		Body:    expand(route.Body),
+	}
+	if route.Auth != nil {
+		req.Auth = *route.Auth
+	}
+	for _, headers := range []map[string]string{env.Headers, route.Headers} {
+		for key, value := range headers {
+			req.Headers[http.CanonicalHeaderKey(expander.Expand(key))] = expand(value)
+		}
+	}
+
+	req.Auth.Username = expand(req.Auth.Username)
+	req.Auth.Password = expand(req.Auth.Password)
+	req.Auth.Token = expand(req.Auth.Token)
+	req.Auth.TokenURL = expand(req.Auth.TokenURL)
+	req.Auth.ClientID = expand(req.Auth.ClientID)
+	req.Auth.ClientSecret = expand(req.Auth.ClientSecret)
+	req.Auth.RefreshToken = expand(req.Auth.RefreshToken)
+	req.Auth.Audience = expand(req.Auth.Audience)
+
+	for key, values := range query {
+		for i := range values {
+			values[i] = expand(values[i])
+		}
+		query[key] = values
+	}
+
+	if err := expander.Err(); err != nil {
+		return nil, fmt.Errorf("route %s in environment %s: %w", route.Name, env.Name, err)
+	}
+	if resolveErr != nil {
+		return nil, fmt.Errorf("route %s in environment %s: %w", route.Name, env.Name, resolveErr)
+	}
+
+	policy, err := resolvePolicy(service.Policy, env.Policy, route.Policy, overrides.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("route %s in environment %s: %w", route.Name, env.Name, err)
+	}
+	req.Policy = policy
+
+	fullURL, err := buildURL(rawURL, query)
+	if err != nil {
+		return nil, fmt.Errorf("route %s in environment %s: %w", route.Name, env.Name, err)
+	}
+	req.URL = fullURL
+
+	return req, nil
+}
+
+// mergeQuery combines query parameter maps. A key in a later map replaces
+// all values of that key in earlier maps.
+func mergeQuery(queries ...map[string][]string) map[string][]string {
+	merged := make(map[string][]string)
+	for _, query := range queries {
+		for key, values := range query {
+			merged[key] = append([]string(nil), values...)
+		}
+	}
+	return merged
+}
+
+// buildURL appends the encoded query parameters to rawURL, keeping any
+// query string already present in it
+func buildURL(rawURL string, query map[string][]string) (string, error) {
+	if len(query) == 0 {
+		return rawURL, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	encoded := url.Values(query).Encode()
+	if u.RawQuery != "" {
+		u.RawQuery += "&" + encoded
+	} else {
+		u.RawQuery = encoded
+	}
+	return u.String(), nil
+}
+
+// Send sends a prepared request without printing anything, retrying as
+// its policy allows. Attempts are logged.
+func Send(ctx context.Context, prepared *PreparedRequest) (*Response, error) {
+	return prepared.send(ctx, nil)
+}
+
+// newHTTPRequest builds the HTTP request, including its authentication.
+// Fetching tokens and sending the request are bound to ctx, and fetching
+// tokens to the policy's timeout.
+func (p *PreparedRequest) newHTTPRequest(ctx context.Context) (*http.Request, error) {
+	var body io.Reader
+	if p.Body != "" {
+		body = strings.NewReader(p.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, p.Method, p.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range p.Headers {
+		req.Header.Set(key, value)
+	}
+
+	authenticator, err := newAuthenticator(p.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("configuring authentication: %w", err)
+	}
+	// Fetching a token gets the same timeout as the request itself. The
+	// shallow copy shares req's headers, so the credentials end up on req.
+	authReq := req
+	if p.Policy.Timeout > 0 {
+		authCtx, cancel := context.WithTimeout(ctx, p.Policy.Timeout)
+		defer cancel()
+		authReq = req.WithContext(authCtx)
+	}
+	if err := authenticator.Authenticate(authReq); err != nil {
+		return nil, fmt.Errorf("authenticating request: %w", err)
+	}
+
+	return req, nil
+}
+
+// do sends the request and reads the whole response, stopping when the
+// request's context is cancelled
+func do(req *http.Request, timeout time.Duration) (*Response, error) {
+	client := &http.Client{Timeout: timeout}
+
+	trace := &tracer{start: time.Now()}
+	resp, err := client.Do(req.WithContext(trace.withTrace(req.Context())))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	trace.bodyEnd = time.Now()
+
+	return &Response{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Proto:      resp.Proto,
+		Headers:    resp.Header,
+		Body:       body,
+		Latency:    trace.bodyEnd.Sub(trace.start),
+		Timings:    trace.timings(resp.Proto, resp.TLS),
+	}, nil
+}
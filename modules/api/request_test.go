@@ -0,0 +1,160 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+func TestPrepareRequest(t *testing.T) {
+	service := &config.Service{
+		Name:      "Users",
+		Variables: map[string]string{"version": "v1"},
+	}
+	env := &config.Environment{
+		Name:      "staging",
+		BaseURL:   "https://{{tenant}}.example.com",
+		Headers:   map[string]string{"X-Tenant": "{{tenant}}"},
+		Query:     map[string][]string{"api-version": {"1"}, "page": {"1"}},
+		Variables: map[string]string{"tenant": "acme", "version": "v2"},
+	}
+	route := &config.Route{
+		Name:    "list",
+		Method:  "GET",
+		Path:    "/{{version}}/users?active=true",
+		Query:   map[string][]string{"page": {"{{page}}"}, "tag": {"a b", "c&d"}},
+		Headers: map[string]string{"x-tenant": "route-{{tenant}}", "X-Session": "{{session}}"},
+		Auth:    &config.Auth{Type: "bearer", Token: "{{user}}-token"},
+		Body:    `{"user": "{{user}}"}`,
+	}
+	overrides := Overrides{
+		Variables: map[string]string{"user": "hippo", "page": "3"},
+		Query:     map[string][]string{"api-version": {"2"}},
+	}
+
+	req, err := PrepareRequest(service, route, env, overrides, map[string]string{"tenant": "stored", "page": "2", "session": "s1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The extracted tenant overrides the environment's
+	if want := "https://stored.example.com/v2/users?active=true&api-version=2&page=3&tag=a+b&tag=c%26d"; req.URL != want {
+		t.Errorf("expected URL %q, got %q", want, req.URL)
+	}
+	if got := req.Headers["X-Tenant"]; got != "route-stored" {
+		t.Errorf("expected route X-Tenant header to replace the environment's, got %q", got)
+	}
+	if got := req.Headers["X-Session"]; got != "s1" {
+		t.Errorf("expected stored variable to fill names the config doesn't define, got %q", got)
+	}
+	if req.Auth.Type != "bearer" || req.Auth.Token != "hippo-token" {
+		t.Errorf("expected route auth to replace the environment's, got %+v", req.Auth)
+	}
+	if want := `{"user": "hippo"}`; req.Body != want {
+		t.Errorf("expected body %q, got %q", want, req.Body)
+	}
+	if got := route.Query["page"][0]; got != "{{page}}" {
+		t.Errorf("route config was modified while preparing request: %q", got)
+	}
+}
+
+func TestPrepareRequestMissingVariables(t *testing.T) {
+	service := &config.Service{Name: "Users"}
+	env := &config.Environment{Name: "staging", BaseURL: "https://{{host}}"}
+	route := &config.Route{Name: "get", Method: "GET", Path: "/users/{{id}}"}
+
+	_, err := PrepareRequest(service, route, env, Overrides{}, nil)
+	if err == nil {
+		t.Fatal("expected error for unresolved variables")
+	}
+	if want := "route get in environment staging: unresolved variables: host, id"; err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
+
+func TestPrepareRequestSecrets(t *testing.T) {
+	t.Setenv("HC_TEST_API_TOKEN", "env-secret-token")
+	service := &config.Service{Name: "Users"}
+	env := &config.Environment{
+		Name:      "prod",
+		BaseURL:   "https://api.example.com",
+		Auth:      config.Auth{Type: "bearer", Token: "{{token}}"},
+		Variables: map[string]string{"token": "${env:HC_TEST_API_TOKEN}"},
+	}
+	route := &config.Route{Name: "get", Method: "GET", Path: "/users"}
+
+	req, err := PrepareRequest(service, route, env, Overrides{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Auth.Token != "env-secret-token" {
+		t.Errorf("expected the token from the environment, got %q", req.Auth.Token)
+	}
+	httpReq, err := req.newHTTPRequest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := newOutputRequest(httpReq, "", false); out.Headers.Get("Authorization") != "Bearer REDACTED" {
+		t.Errorf("expected the resolved token to be masked, got %q", out.Headers.Get("Authorization"))
+	}
+
+	env.Auth.Token = "${env:HC_TEST_UNSET_TOKEN}"
+	if _, err := PrepareRequest(service, route, env, Overrides{}, nil); err == nil {
+		t.Error("expected an error for an unset environment variable")
+	}
+}
+
+func TestPrepareRequestDoesNotResolveSubstitutedValues(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	injected := "${cmd:touch " + marker + "}"
+	service := &config.Service{Name: "Users"}
+	env := &config.Environment{Name: "prod", BaseURL: "https://api.example.com"}
+	route := &config.Route{
+		Name: "update", Method: "POST", Path: "/users",
+		Headers: map[string]string{"X-Session": "{{session}}"},
+		Body:    `{"name": "{{name}}"}`,
+	}
+	overrides := Overrides{Variables: map[string]string{"name": injected}}
+
+	req, err := PrepareRequest(service, route, env, overrides, map[string]string{"session": injected})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("expected references in substituted values not to be resolved")
+	}
+	if req.Body != `{"name": "`+injected+`"}` || req.Headers["X-Session"] != injected {
+		t.Errorf("expected substituted values to be kept as they are, got %q and %q", req.Body, req.Headers["X-Session"])
+	}
+}
+
+func TestSendCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := Send(ctx, &PreparedRequest{Method: "GET", URL: server.URL})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancellation error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("request kept running for %s after being cancelled", elapsed)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+// retryOnNetwork is the retry_on entry matching transport errors
+const retryOnNetwork = "network"
+
+// defaultPolicy applies to settings no config level or flag sets
+var defaultPolicy = config.Policy{
+	Timeout:    30 * time.Second,
+	Retries:    new(int),
+	RetryOn:    []string{retryOnNetwork, "429", "502", "503", "504"},
+	Backoff:    200 * time.Millisecond,
+	MaxBackoff: 10 * time.Second,
+}
+
+// globalPolicy is the top-level policy of the API config, set by Setup
+var globalPolicy config.Policy
+
+// Attempt describes one try of a request
+type Attempt struct {
+	Number   int
+	Of       int           // Attempts allowed by the policy
+	Request  *http.Request // The request as sent, including its authentication
+	Response *Response
+	Err      error
+	Delay    time.Duration // Wait before the next attempt, zero if there is none
+}
+
+func (a Attempt) String() string {
+	outcome := ""
+	if a.Err != nil {
+		outcome = a.Err.Error()
+	} else {
+		outcome = fmt.Sprintf("%s (%s)", a.Response.Status, a.Response.Latency.Round(time.Millisecond))
+	}
+	if a.Delay > 0 {
+		return fmt.Sprintf("Attempt %d/%d: %s, retrying in %s", a.Number, a.Of, outcome, a.Delay.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("Attempt %d/%d: %s", a.Number, a.Of, outcome)
+}
+
+// resolvePolicy merges the policies of every level, the most specific last
+func resolvePolicy(levels ...config.Policy) (config.Policy, error) {
+	policy := defaultPolicy.Override(globalPolicy)
+	for _, level := range levels {
+		policy = policy.Override(level)
+	}
+
+	if *policy.Retries < 0 {
+		return policy, fmt.Errorf("retries must not be negative, got %d", *policy.Retries)
+	}
+	for _, spec := range policy.RetryOn {
+		if strings.EqualFold(spec, retryOnNetwork) {
+			continue
+		}
+		if _, err := StatusMatches(spec, 0); err != nil {
+			return policy, fmt.Errorf("retry_on: %w", err)
+		}
+	}
+	return policy, nil
+}
+
+// send sends the request, retrying as allowed by its policy. onAttempt, if
+// not nil, is called after every attempt. The request is built again for each
+// attempt, so callers printing or recording what was sent should use the
+// attempt's Request.
+func (p *PreparedRequest) send(ctx context.Context, onAttempt func(Attempt)) (*Response, error) {
+	attempts := 1
+	if p.Policy.Retries != nil {
+		attempts += *p.Policy.Retries
+	}
+
+	for n := 1; ; n++ {
+		req, err := p.newHTTPRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := do(req, p.Policy.Timeout)
+
+		attempt := Attempt{Number: n, Of: attempts, Request: req, Response: resp, Err: err}
+		if n < attempts && ctx.Err() == nil && shouldRetry(p.Policy.RetryOn, resp, err) {
+			attempt.Delay = backoff(p.Policy, n)
+		}
+		alogger.Printf("%s %s: %s\n", p.Method, p.URL, attempt)
+		if onAttempt != nil {
+			onAttempt(attempt)
+		}
+
+		if attempt.Delay == 0 {
+			if resp != nil {
+				resp.Attempts = n
+			}
+			return resp, err
+		}
+
+		timer := time.NewTimer(attempt.Delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// shouldRetry reports whether an attempt's outcome matches retry_on
+func shouldRetry(retryOn []string, resp *Response, err error) bool {
+	if err != nil && errors.Is(err, context.Canceled) {
+		return false
+	}
+	for _, spec := range retryOn {
+		if strings.EqualFold(spec, retryOnNetwork) {
+			if err != nil {
+				return true
+			}
+			continue
+		}
+		if resp != nil {
+			if ok, _ := StatusMatches(spec, resp.StatusCode); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// backoff returns the delay after the given attempt: the backoff doubled for
+// each earlier retry, capped at max_backoff, with "equal jitter" so that the
+// delay is between half and all of that value
+func backoff(policy config.Policy, attempt int) time.Duration {
+	delay := policy.Backoff
+	for i := 1; i < attempt && delay < policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	if delay <= 0 {
+		return time.Millisecond
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
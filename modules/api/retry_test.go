@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestResolvePolicy(t *testing.T) {
+	globalPolicy = config.Policy{Timeout: 10 * time.Second, Retries: intPtr(2)}
+	defer func() { globalPolicy = config.Policy{} }()
+
+	policy, err := resolvePolicy(
+		config.Policy{RetryOn: []string{"503"}},  // service
+		config.Policy{Backoff: time.Second},      // environment
+		config.Policy{Timeout: 45 * time.Second}, // route
+		config.Policy{Retries: intPtr(0)},        // flags
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.Timeout != 45*time.Second || *policy.Retries != 0 || policy.Backoff != time.Second ||
+		len(policy.RetryOn) != 1 || policy.MaxBackoff != defaultPolicy.MaxBackoff {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	if _, err := resolvePolicy(config.Policy{Retries: intPtr(-1)}); err == nil {
+		t.Errorf("expected error for negative retries")
+	}
+	if _, err := resolvePolicy(config.Policy{RetryOn: []string{"sometimes"}}); err == nil {
+		t.Errorf("expected error for an invalid retry_on entry")
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	policy := config.Policy{Timeout: time.Second, Retries: intPtr(3), RetryOn: []string{"5xx"}, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	var attempts []Attempt
+	resp, err := (&PreparedRequest{Method: "GET", URL: server.URL, Policy: policy}).send(context.Background(), func(a Attempt) {
+		attempts = append(attempts, a)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 || resp.Attempts != 3 || len(attempts) != 3 {
+		t.Fatalf("expected success on the third attempt, got %d after %d attempts", resp.StatusCode, len(attempts))
+	}
+	if attempts[0].Delay == 0 || attempts[2].Delay != 0 {
+		t.Errorf("expected delays only before retries, got %+v", attempts)
+	}
+	if attempts[2].Request == nil || attempts[2].Request == attempts[0].Request {
+		t.Errorf("expected each attempt to report the request it sent")
+	}
+
+	// Statuses not listed in retry_on are returned immediately
+	calls.Store(0)
+	policy.RetryOn = []string{"network"}
+	resp, _ = (&PreparedRequest{Method: "GET", URL: server.URL, Policy: policy}).send(context.Background(), nil)
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("expected a single attempt, got %d", calls.Load())
+	}
+}
+
+func TestSendRetriesNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	policy := config.Policy{Timeout: time.Second, Retries: intPtr(2), RetryOn: []string{"network"}, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	attempts := 0
+	_, err := (&PreparedRequest{Method: "GET", URL: url, Policy: policy}).send(context.Background(), func(Attempt) { attempts++ })
+	if err == nil || attempts != 3 {
+		t.Errorf("expected 3 failed attempts, got %d (%v)", attempts, err)
+	}
+
+	// Cancellation stops the retries while waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	policy.Backoff, policy.MaxBackoff = time.Hour, time.Hour
+	_, err = (&PreparedRequest{Method: "GET", URL: url, Policy: policy}).send(ctx, func(Attempt) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := config.Policy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, limit := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		for i := 0; i < 20; i++ {
+			if delay := backoff(policy, attempt); delay < limit/2 || delay > limit {
+				t.Fatalf("attempt %d: delay %s outside [%s, %s]", attempt, delay, limit/2, limit)
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pbidwell/hippocurl/internal/jsondiff"
+	"github.com/pbidwell/hippocurl/internal/jsonpath"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+	"github.com/pbidwell/hippocurl/utils"
+
+	"github.com/fatih/color"
+)
+
+// Values of APIModule.Snapshot
+const (
+	SnapshotSave  = "save"  // Store the response as the route's snapshot
+	SnapshotCheck = "check" // Compare the response to the stored snapshot
+)
+
+// SnapshotModes lists the valid values of APIModule.Snapshot
+var SnapshotModes = []string{SnapshotSave, SnapshotCheck}
+
+// snapshotDirName is the directory, inside the hc config directory, that
+// holds snapshots as <service>/<route>/<env>.json
+const snapshotDirName = "snapshots"
+
+// ignoredValue replaces ignored values in saved snapshots, so that they
+// don't change every time the snapshot is saved again
+const ignoredValue = "<ignored>"
+
+// snapshot is the normalized form of a response kept for comparison:
+// volatile headers are dropped and JSON bodies are stored decoded, with
+// sorted keys
+type snapshot struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	JSON        any    `json:"json,omitempty"` // The body, when it is valid JSON
+	Text        string `json:"text,omitempty"` // The body otherwise
+}
+
+// newSnapshot normalizes resp. Secrets are redacted as in the history, so
+// they are neither written to disk nor reported as differences.
+func newSnapshot(resp *Response) *snapshot {
+	snap := &snapshot{Status: resp.StatusCode, ContentType: resp.Headers.Get("Content-Type")}
+	body := redactBody([]byte(secrets.Mask(string(resp.Body))), snap.ContentType)
+	if doc, err := jsonpath.Decode(body); err == nil && len(body) > 0 {
+		snap.JSON = doc
+	} else {
+		snap.Text = string(body)
+	}
+	return snap
+}
+
+// maskIgnored returns value with everything at the ignore paths replaced
+// by ignoredValue
+func maskIgnored(location []any, value any, ignore []*jsonpath.Path) any {
+	for _, path := range ignore {
+		if path.Contains(location) {
+			return ignoredValue
+		}
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			value[key] = maskIgnored(append(location[:len(location):len(location)], key), field, ignore)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = maskIgnored(append(location[:len(location):len(location)], i), item, ignore)
+		}
+	}
+	return value
+}
+
+// snapshotPath returns the file holding the snapshot of a route in an
+// environment
+func snapshotPath(configDir, service, route, env string) string {
+	return filepath.Join(configDir, snapshotDirName, safeFileName(service), safeFileName(route), safeFileName(env)+".json")
+}
+
+// safeFileName turns a config name into a single path element
+func safeFileName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if name == "" || name == "." || name == ".." {
+		name = "_" + name
+	}
+	return name
+}
+
+func saveSnapshot(path string, snap *snapshot) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(snap); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// loadSnapshot reads a saved snapshot, returning nil if there is none
+func loadSnapshot(path string) (*snapshot, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parsing snapshot %s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// compareSnapshots returns the differences between a saved snapshot and the
+// current one, skipping the ignore paths, and the number of ignored ones
+func compareSnapshots(saved, current *snapshot, ignore []*jsonpath.Path) ([]jsondiff.Difference, int) {
+	var diffs []jsondiff.Difference
+	if saved.Status != current.Status {
+		diffs = append(diffs, jsondiff.Difference{Path: "status", Kind: jsondiff.Changed, Left: saved.Status, Right: current.Status})
+	}
+	if saved.ContentType != current.ContentType {
+		diffs = append(diffs, jsondiff.Difference{Path: "header Content-Type", Kind: jsondiff.Changed, Left: saved.ContentType, Right: current.ContentType})
+	}
+	if saved.Text != current.Text || (saved.JSON == nil) != (current.JSON == nil) {
+		diffs = append(diffs, jsondiff.Difference{Path: "body", Kind: jsondiff.Changed, Left: saved.body(), Right: current.body()})
+		return diffs, 0
+	}
+
+	result := jsondiff.Compare(saved.JSON, current.JSON, ignore)
+	for _, diff := range result.Differences {
+		diff.Path = "body" + strings.TrimPrefix(diff.Path, "$")
+		diffs = append(diffs, diff)
+	}
+	return diffs, result.Ignored
+}
+
+// body returns the snapshot's body as text
+func (s *snapshot) body() string {
+	if s.JSON == nil {
+		return s.Text
+	}
+	return jsonpath.Format(s.JSON)
+}
+
+// printSnapshotDiff prints each difference as the removed snapshot value in
+// red and the added current value in green
+func printSnapshotDiff(diffs []jsondiff.Difference) {
+	removed, added := color.New(color.FgRed), color.New(color.FgGreen)
+	for _, diff := range diffs {
+		fmt.Println(diff.Path)
+		if diff.Kind != jsondiff.Added {
+			removed.Println(indentLines("- ", formatSnapshotValue(diff.Path, diff.Left)))
+		}
+		if diff.Kind != jsondiff.Removed {
+			added.Println(indentLines("+ ", formatSnapshotValue(diff.Path, diff.Right)))
+		}
+	}
+}
+
+// formatSnapshotValue renders a differing value as indented JSON, except
+// whole bodies, which are shown as they are
+func formatSnapshotValue(path string, value any) string {
+	if text, ok := value.(string); ok && path == "body" {
+		return text
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func indentLines(prefix, text string) string {
+	return prefix + strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", "\n"+prefix)
+}
+
+// checkSnapshot saves the response as the route's snapshot or compares it to
+// the saved one, reporting whether it matched
+func (a APIModule) checkSnapshot(path string, resp *Response, ignore []*jsonpath.Path) (bool, error) {
+	current := newSnapshot(resp)
+	if a.Snapshot == SnapshotSave {
+		current.JSON = maskIgnored(nil, current.JSON, ignore)
+		if err := saveSnapshot(path, current); err != nil {
+			return false, err
+		}
+		alogger.Printf("Saved snapshot %s\n", path)
+		utils.Print("Snapshot", utils.Header1)
+		utils.PrintFieldValuePair("Saved", path)
+		return true, nil
+	}
+
+	saved, err := loadSnapshot(path)
+	if err != nil {
+		return false, err
+	}
+	if saved == nil {
+		return false, fmt.Errorf("no snapshot at %s, save one with --snapshot save", path)
+	}
+
+	diffs, ignored := compareSnapshots(saved, current, ignore)
+	alogger.Printf("Checked snapshot %s: %d differences, %d ignored\n", path, len(diffs), ignored)
+	utils.Print("Snapshot", utils.Header1)
+	utils.PrintFieldValuePair("Compared to", path)
+	if len(diffs) == 0 {
+		color.New(color.FgGreen).Printf("MATCH (%d ignored)\n", ignored)
+		return true, nil
+	}
+	printSnapshotDiff(diffs)
+	color.New(color.FgRed).Printf("MISMATCH: %d differences, %d ignored\n", len(diffs), ignored)
+	return false, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := snapshotPath(t.TempDir(), "Users", "get/user", "dev")
+	if filepath.Base(filepath.Dir(path)) != "get_user" {
+		t.Errorf("expected the route name to be a single path element, got %s", path)
+	}
+
+	ignore, _ := parseIgnorePaths([]string{"$.fetched_at"})
+	headers := http.Header{"Content-Type": {"application/json"}}
+	resp := &Response{StatusCode: 200, Headers: headers, Body: []byte(`{"id":1,"fetched_at":"10:00","access_token":"abc123"}`)}
+
+	module := APIModule{Snapshot: SnapshotSave}
+	if matched, err := module.checkSnapshot(path, resp, ignore); err != nil || !matched {
+		t.Fatalf("unexpected save result %v, %v", matched, err)
+	}
+	saved, err := loadSnapshot(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc := saved.JSON.(map[string]any); doc["fetched_at"] != ignoredValue {
+		t.Errorf("expected the ignored value to be masked, got %v", doc)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "abc123") {
+		t.Errorf("expected the token to be redacted from the snapshot:\n%s", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the snapshot to be only readable by the user, got %v", info.Mode())
+	}
+
+	module.Snapshot = SnapshotCheck
+	later := &Response{StatusCode: 200, Headers: headers, Body: []byte(`{"fetched_at":"11:00","id":1,"access_token":"def456"}`)}
+	if matched, err := module.checkSnapshot(path, later, ignore); err != nil || !matched {
+		t.Errorf("expected the snapshot to match, got %v, %v", matched, err)
+	}
+
+	changed := &Response{StatusCode: 200, Headers: headers, Body: []byte(`{"id":2,"fetched_at":"11:00","access_token":"abc123"}`)}
+	diffs, ignored := compareSnapshots(saved, newSnapshot(changed), ignore)
+	if len(diffs) != 1 || diffs[0].Path != "body.id" || ignored != 1 {
+		t.Errorf("expected body.id to differ with 1 ignored, got %+v, %d", diffs, ignored)
+	}
+	if matched, _ := module.checkSnapshot(path, changed, ignore); matched {
+		t.Error("expected a mismatch")
+	}
+}
+
+func TestSnapshotMissing(t *testing.T) {
+	module := APIModule{Snapshot: SnapshotCheck}
+	resp := &Response{StatusCode: 200, Headers: http.Header{}}
+	if _, err := module.checkSnapshot(filepath.Join(t.TempDir(), "missing.json"), resp, nil); err == nil {
+		t.Error("expected an error for a missing snapshot")
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pbidwell/hippocurl/utils"
+)
+
+// Phase is one step of a request, timed from the start of the request
+type Phase struct {
+	Name     string
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// Timings breaks a request down into the phases of its waterfall. Phases
+// that didn't happen, such as DNS and connecting on a reused connection,
+// are zero.
+type Timings struct {
+	DNS        time.Duration
+	Connect    time.Duration
+	TLS        time.Duration
+	Wait       time.Duration // From writing the request to the first response byte
+	Transfer   time.Duration // Reading the response body
+	Total      time.Duration
+	Reused     bool   // Whether the request used a kept-alive connection
+	RemoteAddr string // Address of the server the connection was made to
+	Protocol   string // e.g. "HTTP/2.0"
+	TLSVersion string // e.g. "TLS 1.3", empty for plain HTTP
+
+	phases []Phase
+}
+
+// Phases returns the phases that happened, in order
+func (t *Timings) Phases() []Phase {
+	return t.phases
+}
+
+// tracer records the httptrace events of a request
+type tracer struct {
+	mu                               sync.Mutex
+	start                            time.Time
+	dnsStart, dnsDone                time.Time
+	connectStart, connectDone        time.Time
+	tlsStart, tlsDone                time.Time
+	wroteRequest, firstByte, bodyEnd time.Time
+	reused                           bool
+	remoteAddr                       string
+}
+
+// withTrace returns ctx instrumented to record the phases of a request
+func (t *tracer) withTrace(ctx context.Context) context.Context {
+	now := func(field *time.Time, keepFirst bool) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if keepFirst && !field.IsZero() {
+			return
+		}
+		*field = time.Now()
+	}
+
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { now(&t.dnsStart, true) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { now(&t.dnsDone, false) },
+		// Dual-stack hosts may dial several addresses; time from the first
+		// attempt to the connection that succeeded
+		ConnectStart: func(string, string) { now(&t.connectStart, true) },
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				now(&t.connectDone, false)
+			}
+		},
+		TLSHandshakeStart: func() { now(&t.tlsStart, true) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { now(&t.tlsDone, false) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { now(&t.wroteRequest, false) },
+		GotFirstResponseByte: func() { now(&t.firstByte, false) },
+	})
+}
+
+// timings computes the phases once the body has been read
+func (t *tracer) timings(proto string, state *tls.ConnectionState) *Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := &Timings{
+		Total:      t.bodyEnd.Sub(t.start),
+		Reused:     t.reused,
+		RemoteAddr: t.remoteAddr,
+		Protocol:   proto,
+	}
+	if state != nil {
+		timings.TLSVersion = tls.VersionName(state.Version)
+	}
+
+	add := func(name string, from, to time.Time, field *time.Duration) {
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			return
+		}
+		*field = to.Sub(from)
+		timings.phases = append(timings.phases, Phase{Name: name, Start: from.Sub(t.start), Duration: *field})
+	}
+	add("DNS lookup", t.dnsStart, t.dnsDone, &timings.DNS)
+	add("TCP connect", t.connectStart, t.connectDone, &timings.Connect)
+	add("TLS handshake", t.tlsStart, t.tlsDone, &timings.TLS)
+	add("Wait (TTFB)", t.wroteRequest, t.firstByte, &timings.Wait)
+	add("Content transfer", t.firstByte, t.bodyEnd, &timings.Transfer)
+	return timings
+}
+
+// waterfallWidth is the width of the bars drawn by printTimings
+const waterfallWidth = 40
+
+// printTimings prints the phases of a request as a waterfall, followed by
+// connection details
+func printTimings(t *Timings) {
+	utils.Print("Timing", utils.Header2)
+	for _, phase := range t.Phases() {
+		offset, width := 0, 0
+		if t.Total > 0 {
+			offset = int(int64(waterfallWidth) * int64(phase.Start) / int64(t.Total))
+			width = int(int64(waterfallWidth) * int64(phase.Duration) / int64(t.Total))
+		}
+		offset = min(offset, waterfallWidth-1)
+		width = max(1, min(width, waterfallWidth-offset))
+		bar := strings.Repeat(" ", offset) + strings.Repeat("█", width) + strings.Repeat(" ", waterfallWidth-offset-width)
+		fmt.Printf("%-17s %s %9s\n", phase.Name, bar, formatDuration(phase.Duration))
+	}
+	fmt.Printf("%-17s %s %9s\n", "Total", strings.Repeat(" ", waterfallWidth), formatDuration(t.Total))
+
+	connection := "new"
+	if t.Reused {
+		connection = "reused"
+	}
+	if t.RemoteAddr != "" {
+		connection = fmt.Sprintf("%s (%s)", connection, t.RemoteAddr)
+	}
+	utils.PrintFieldValuePair("Connection", connection)
+	utils.PrintFieldValuePair("Protocol", t.Protocol)
+	if t.TLSVersion != "" {
+		utils.PrintFieldValuePair("TLS", t.TLSVersion)
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(100 * time.Microsecond).String()
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendTimings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req := &PreparedRequest{Method: "GET", URL: server.URL}
+	first, err := Send(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	timings := first.Timings
+	if timings == nil {
+		t.Fatal("expected timings")
+	}
+	if timings.Reused {
+		t.Error("expected the first request to open a connection")
+	}
+	if timings.Connect <= 0 {
+		t.Errorf("expected a connect time, got %s", timings.Connect)
+	}
+	if timings.Wait < 20*time.Millisecond {
+		t.Errorf("expected the wait to include the server's delay, got %s", timings.Wait)
+	}
+	if timings.Total != first.Latency || timings.Total < timings.Connect+timings.Wait {
+		t.Errorf("total %s doesn't cover the phases %v", timings.Total, timings.Phases())
+	}
+	if timings.Protocol != "HTTP/1.1" || timings.TLSVersion != "" {
+		t.Errorf("expected plain HTTP/1.1, got %q %q", timings.Protocol, timings.TLSVersion)
+	}
+	if timings.RemoteAddr != server.Listener.Addr().String() {
+		t.Errorf("expected remote address %s, got %s", server.Listener.Addr(), timings.RemoteAddr)
+	}
+
+	second, err := Send(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !second.Timings.Reused || second.Timings.Connect != 0 {
+		t.Errorf("expected the second request to reuse the connection, got %+v", second.Timings)
+	}
+	for _, phase := range second.Timings.Phases() {
+		if phase.Name == "TCP connect" {
+			t.Errorf("unexpected connect phase on a reused connection")
+		}
+	}
+}
+
+func TestSendTimingsTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	trace := &tracer{start: time.Now()}
+	req, _ := http.NewRequestWithContext(trace.withTrace(context.Background()), "GET", server.URL, nil)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	trace.bodyEnd = time.Now()
+
+	timings := trace.timings(resp.Proto, resp.TLS)
+	if timings.TLS <= 0 {
+		t.Errorf("expected a TLS handshake time, got %s", timings.TLS)
+	}
+	if timings.TLSVersion != "TLS 1.3" {
+		t.Errorf("expected TLS 1.3, got %q", timings.TLSVersion)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package config
+
+import (
+	"context"
+	"fmt"
+
+	hcconfig "github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/modules"
+	"github.com/pbidwell/hippocurl/utils"
+
+	"github.com/fatih/color"
+)
+
+// ValidateModule implements the HippoModule interface
+type ValidateModule struct {
+	Strict bool // Fail on warnings too
+}
+
+func (v ValidateModule) Name() string {
+	return "validate"
+}
+
+func (v ValidateModule) Description() string {
+	return "Checks the API config files for mistakes."
+}
+
+func (v ValidateModule) Use() string {
+	return "config validate"
+}
+
+func (v ValidateModule) Logo() string {
+	return "✅"
+}
+
+// ChecksConfig lets the module run when the API config can't be read
+func (v ValidateModule) ChecksConfig() {}
+
+func (v ValidateModule) Execute(ctx context.Context, app *hcconfig.App, args []string) error {
+	utils.Print(v.Use(), utils.ModuleTitle)
+
+	problems, err := hcconfig.Validate(app.APIConfigPath, app.ServicesDir)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+
+	utils.PrintFieldValuePair("Config", app.APIConfigPath)
+	errorCount, warningCount := 0, 0
+	if len(problems) > 0 {
+		utils.Print("Problems", utils.Header1)
+	}
+	for _, problem := range problems {
+		if problem.Warning {
+			warningCount++
+			color.New(color.FgYellow).Print("warning ")
+		} else {
+			errorCount++
+			color.New(color.FgRed).Print("error   ")
+		}
+		fmt.Println(problem.String())
+	}
+	app.Logger.Printf("Validated %s: %d errors, %d warnings", app.APIConfigPath, errorCount, warningCount)
+
+	summary := fmt.Sprintf("%d errors, %d warnings", errorCount, warningCount)
+	if errorCount > 0 || (v.Strict && warningCount > 0) {
+		color.New(color.FgRed).Println(summary)
+		return modules.Exit(modules.ExitAssertion, nil)
+	}
+	if warningCount > 0 {
+		color.New(color.FgYellow).Println(summary)
+		return nil
+	}
+	color.New(color.FgGreen).Println("Config is valid")
+	return nil
+}
@@ -0,0 +1,559 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	hcconfig "github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/utils"
+
+	"github.com/manifoldco/promptui"
+)
+
+// AddModule implements the HippoModule interface
+type AddModule struct{}
+
+// errCancelled ends the wizard when a prompt is interrupted
+var errCancelled = errors.New("cancelled")
+
+// Menu entries
+const (
+	menuAddService    = "Add a service"
+	menuEditService   = "Edit a service"
+	menuDeleteService = "Delete a service"
+	menuAddEnv        = "Add an environment"
+	menuEditEnv       = "Edit an environment"
+	menuDeleteEnv     = "Delete an environment"
+	menuAddRoute      = "Add a route"
+	menuEditRoute     = "Edit a route"
+	menuDeleteRoute   = "Delete a route"
+	menuBack          = "Back"
+	menuDone          = "Done"
+)
+
+// routeMethods are offered when adding a route
+var routeMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions}
+
+// authField is an auth setting asked for by the wizard
+type authField struct {
+	Label    string
+	Required bool
+	Secret   bool // Masked while typing
+	Value    func(auth *hcconfig.Auth) *string
+}
+
+// authFields lists the settings of each auth type
+var authFields = map[string][]authField{
+	"basic": {
+		{Label: "Username", Required: true, Value: func(a *hcconfig.Auth) *string { return &a.Username }},
+		{Label: "Password", Secret: true, Value: func(a *hcconfig.Auth) *string { return &a.Password }},
+	},
+	"bearer": {
+		{Label: "Token", Required: true, Secret: true, Value: func(a *hcconfig.Auth) *string { return &a.Token }},
+	},
+	"oauth2_client_credentials": {
+		{Label: "Token URL", Required: true, Value: func(a *hcconfig.Auth) *string { return &a.TokenURL }},
+		{Label: "Client ID", Required: true, Value: func(a *hcconfig.Auth) *string { return &a.ClientID }},
+		{Label: "Client secret", Secret: true, Value: func(a *hcconfig.Auth) *string { return &a.ClientSecret }},
+		{Label: "Audience", Value: func(a *hcconfig.Auth) *string { return &a.Audience }},
+	},
+	"oauth2_refresh_token": {
+		{Label: "Token URL", Required: true, Value: func(a *hcconfig.Auth) *string { return &a.TokenURL }},
+		{Label: "Refresh token", Required: true, Secret: true, Value: func(a *hcconfig.Auth) *string { return &a.RefreshToken }},
+		{Label: "Client ID", Value: func(a *hcconfig.Auth) *string { return &a.ClientID }},
+		{Label: "Client secret", Secret: true, Value: func(a *hcconfig.Auth) *string { return &a.ClientSecret }},
+	},
+}
+
+func (a AddModule) Name() string {
+	return "add"
+}
+
+func (a AddModule) Description() string {
+	return "Walks through adding, editing and deleting services, environments and routes."
+}
+
+func (a AddModule) Use() string {
+	return "config add"
+}
+
+func (a AddModule) Logo() string {
+	return "🧙"
+}
+
+func (a AddModule) Execute(ctx context.Context, app *hcconfig.App, args []string) error {
+	utils.Print(a.Use(), utils.ModuleTitle)
+
+	w := &wizard{app: app, docs: make(map[string]*hcconfig.Document)}
+	err := w.run()
+	if errors.Is(err, errCancelled) {
+		utils.Print("Cancelled.", utils.NormalText)
+		return nil
+	}
+	return err
+}
+
+// wizard edits the config files defining the services
+type wizard struct {
+	app  *hcconfig.App
+	docs map[string]*hcconfig.Document // Loaded config files, by path
+}
+
+func (w *wizard) run() error {
+	for {
+		items := []string{menuAddService}
+		if len(w.app.APIConfig.Services) > 0 {
+			items = append(items, menuEditService, menuDeleteService)
+		}
+		choice, err := selectItem("What would you like to do?", append(items, menuDone))
+		if err != nil {
+			return err
+		}
+
+		switch choice {
+		case menuAddService:
+			name, err := w.addService()
+			if err != nil {
+				return err
+			}
+			if err := w.editService(name); err != nil {
+				return err
+			}
+		case menuEditService:
+			name, err := selectItem("Select a Service", w.app.APIConfig.GetServiceNames())
+			if err != nil {
+				return err
+			}
+			if err := w.editService(name); err != nil {
+				return err
+			}
+		case menuDeleteService:
+			if err := w.deleteService(); err != nil {
+				return err
+			}
+		case menuDone:
+			return nil
+		}
+	}
+}
+
+// document returns the config file defining a service
+func (w *wizard) document(serviceName string) (*hcconfig.Document, error) {
+	path := w.app.ServiceFile(serviceName)
+	if doc, ok := w.docs[path]; ok {
+		return doc, nil
+	}
+	doc, err := hcconfig.LoadDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	w.docs[path] = doc
+	return doc, nil
+}
+
+// save writes a config file after a change, described by what
+func (w *wizard) save(doc *hcconfig.Document, what string) error {
+	if err := doc.Save(); err != nil {
+		return err
+	}
+	w.app.Logger.Printf("Config wizard: %s in %s", what, doc.Path)
+	utils.Print(fmt.Sprintf("%s in %s", what, doc.Path), utils.Hint)
+	return nil
+}
+
+func (w *wizard) addService() (string, error) {
+	name, err := prompt("Service name", "", func(value string) error {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return errors.New("a name is required")
+		}
+		if w.app.APIConfig.GetServiceByName(value) != nil {
+			return fmt.Errorf("service %s already exists", value)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	doc, err := w.document(name)
+	if err != nil {
+		return "", err
+	}
+	if err := doc.AddService(hcconfig.Service{Name: name}); err != nil {
+		return "", err
+	}
+	if err := w.save(doc, fmt.Sprintf("Added service %s", name)); err != nil {
+		return "", err
+	}
+	w.app.APIConfig.Services = append(w.app.APIConfig.Services, hcconfig.Service{Name: name, File: doc.Path})
+	return name, nil
+}
+
+func (w *wizard) deleteService() error {
+	name, err := selectItem("Delete which Service?", w.app.APIConfig.GetServiceNames())
+	if err != nil {
+		return err
+	}
+	if !confirm(fmt.Sprintf("Delete service %s with all its environments and routes", name)) {
+		return nil
+	}
+	doc, err := w.document(name)
+	if err != nil {
+		return err
+	}
+	if err := doc.RemoveService(name); err != nil {
+		return err
+	}
+	if err := w.save(doc, fmt.Sprintf("Deleted service %s", name)); err != nil {
+		return err
+	}
+	w.app.APIConfig.Services = slices.DeleteFunc(w.app.APIConfig.Services, func(s hcconfig.Service) bool { return s.Name == name })
+	return nil
+}
+
+// editService offers changes to a service's environments and routes until
+// the user goes back
+func (w *wizard) editService(serviceName string) error {
+	doc, err := w.document(serviceName)
+	if err != nil {
+		return err
+	}
+	for {
+		envs, err := doc.EnvironmentNames(serviceName)
+		if err != nil {
+			return err
+		}
+		routes, err := doc.RouteNames(serviceName)
+		if err != nil {
+			return err
+		}
+
+		items := []string{menuAddEnv}
+		if len(envs) > 0 {
+			items = append(items, menuEditEnv, menuDeleteEnv)
+		}
+		items = append(items, menuAddRoute)
+		if len(routes) > 0 {
+			items = append(items, menuEditRoute, menuDeleteRoute)
+		}
+		choice, err := selectItem(fmt.Sprintf("Service %s", serviceName), append(items, menuBack))
+		if err != nil {
+			return err
+		}
+
+		switch choice {
+		case menuAddEnv:
+			err = w.editEnvironment(doc, serviceName, nil, envs)
+		case menuEditEnv:
+			err = w.pick("Select an Environment", envs, func(name string) error {
+				env, err := doc.Environment(serviceName, name)
+				if err != nil {
+					return err
+				}
+				return w.editEnvironment(doc, serviceName, env, envs)
+			})
+		case menuDeleteEnv:
+			err = w.pick("Delete which Environment?", envs, func(name string) error {
+				if !confirm(fmt.Sprintf("Delete environment %s", name)) {
+					return nil
+				}
+				if err := doc.RemoveEnvironment(serviceName, name); err != nil {
+					return err
+				}
+				return w.save(doc, fmt.Sprintf("Deleted environment %s/%s", serviceName, name))
+			})
+		case menuAddRoute:
+			err = w.editRoute(doc, serviceName, nil, routes)
+		case menuEditRoute:
+			err = w.pick("Select a Route", routes, func(name string) error {
+				route, err := doc.Route(serviceName, name)
+				if err != nil {
+					return err
+				}
+				return w.editRoute(doc, serviceName, route, routes)
+			})
+		case menuDeleteRoute:
+			err = w.pick("Delete which Route?", routes, func(name string) error {
+				if !confirm(fmt.Sprintf("Delete route %s", name)) {
+					return nil
+				}
+				if err := doc.RemoveRoute(serviceName, name); err != nil {
+					return err
+				}
+				return w.save(doc, fmt.Sprintf("Deleted route %s/%s", serviceName, name))
+			})
+		case menuBack:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// pick selects one of names and passes it to action
+func (w *wizard) pick(label string, names []string, action func(name string) error) error {
+	name, err := selectItem(label, names)
+	if err != nil {
+		return err
+	}
+	return action(name)
+}
+
+// editEnvironment asks for the settings of a new environment, or of env
+// when editing one. Settings the wizard doesn't cover are kept.
+func (w *wizard) editEnvironment(doc *hcconfig.Document, serviceName string, env *hcconfig.Environment, existing []string) error {
+	adding := env == nil
+	if adding {
+		name, err := prompt("Environment name", "", uniqueName("environment", existing))
+		if err != nil {
+			return err
+		}
+		env = &hcconfig.Environment{Name: name}
+	}
+
+	baseURL, err := prompt("Base URL", env.BaseURL, func(value string) error {
+		if value == "" {
+			return errors.New("a base URL is required")
+		}
+		return hcconfig.CheckURL(value)
+	})
+	if err != nil {
+		return err
+	}
+	env.BaseURL = baseURL
+
+	if env.Auth, err = promptAuth(env.Auth); err != nil {
+		return err
+	}
+	if env.Headers, err = promptHeaders(env.Headers); err != nil {
+		return err
+	}
+	return w.saveEnvironment(doc, serviceName, *env, adding)
+}
+
+// saveEnvironment adds env to a service, or replaces the environment of the
+// same name, and writes the config file
+func (w *wizard) saveEnvironment(doc *hcconfig.Document, serviceName string, env hcconfig.Environment, adding bool) error {
+	var err error
+	if adding {
+		err = doc.AddEnvironment(serviceName, env)
+	} else {
+		err = doc.SetEnvironment(serviceName, env)
+	}
+	if err != nil {
+		return err
+	}
+	return w.save(doc, fmt.Sprintf("Saved environment %s/%s", serviceName, env.Name))
+}
+
+// editRoute asks for the settings of a new route, or of route when editing
+// one. Settings the wizard doesn't cover, such as assertions, are kept.
+func (w *wizard) editRoute(doc *hcconfig.Document, serviceName string, route *hcconfig.Route, existing []string) error {
+	adding := route == nil
+	if adding {
+		name, err := prompt("Route name", "", uniqueName("route", existing))
+		if err != nil {
+			return err
+		}
+		route = &hcconfig.Route{Name: name}
+	}
+
+	description, err := prompt("Description (optional)", route.Description, nil)
+	if err != nil {
+		return err
+	}
+	route.Description = description
+
+	methods := routeMethods
+	if route.Method != "" && !slices.Contains(methods, strings.ToUpper(route.Method)) {
+		methods = append([]string{route.Method}, methods...)
+	}
+	selectMethod := promptui.Select{Label: "Method", Items: methods, CursorPos: max(slices.Index(methods, strings.ToUpper(route.Method)), 0)}
+	if _, route.Method, err = selectMethod.Run(); err != nil {
+		return errCancelled
+	}
+
+	path, err := prompt("Path, e.g. /users/{{id}}", route.Path, func(value string) error {
+		if !strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "{{") {
+			return errors.New("the path must start with /")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	route.Path = path
+
+	if route.Headers, err = promptHeaders(route.Headers); err != nil {
+		return err
+	}
+	if route.Method != http.MethodGet && route.Method != http.MethodHead {
+		body, err := prompt("Body (optional)", route.Body, nil)
+		if err != nil {
+			return err
+		}
+		route.Body = body
+	}
+	return w.saveRoute(doc, serviceName, *route, adding)
+}
+
+// saveRoute adds route to a service, or replaces the route of the same
+// name, and writes the config file
+func (w *wizard) saveRoute(doc *hcconfig.Document, serviceName string, route hcconfig.Route, adding bool) error {
+	var err error
+	if adding {
+		err = doc.AddRoute(serviceName, route)
+	} else {
+		err = doc.SetRoute(serviceName, route)
+	}
+	if err != nil {
+		return err
+	}
+	return w.save(doc, fmt.Sprintf("Saved route %s/%s", serviceName, route.Name))
+}
+
+// promptAuth asks for an auth type and its settings, starting from auth
+func promptAuth(auth hcconfig.Auth) (hcconfig.Auth, error) {
+	current := strings.ToLower(auth.Type)
+	selectType := promptui.Select{Label: "Auth type", Items: hcconfig.AuthTypes, CursorPos: max(slices.Index(hcconfig.AuthTypes, current), 0)}
+	_, authType, err := selectType.Run()
+	if err != nil {
+		return auth, errCancelled
+	}
+	if authType != current {
+		auth = hcconfig.Auth{}
+	}
+	auth.Type = authType
+
+	if len(authFields[authType]) > 0 {
+		utils.Print("Secrets can be given as references such as ${secret:name} or ${env:VAR} to keep them out of the config file.", utils.Hint)
+	}
+	for _, field := range authFields[authType] {
+		value := field.Value(&auth)
+		// Secrets aren't shown for editing: typing replaces them and Enter
+		// keeps them
+		input := promptui.Prompt{Label: field.Label, Default: *value, AllowEdit: !field.Secret}
+		if field.Secret {
+			input.Mask = '*'
+		}
+		if field.Required {
+			input.Validate = required
+		}
+		answer, err := input.Run()
+		if err != nil {
+			return auth, errCancelled
+		}
+		*value = strings.TrimSpace(answer)
+	}
+	return auth, nil
+}
+
+// promptHeaders asks to keep, change or clear each header, then for new
+// ones until an empty answer
+func promptHeaders(headers map[string]string) (map[string]string, error) {
+	result := make(map[string]string)
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		answer, err := prompt("Header (clear to remove)", name+": "+headers[name], validHeader)
+		if err != nil {
+			return nil, err
+		}
+		if name, value, ok := parseHeader(answer); ok {
+			result[name] = value
+		}
+	}
+	for {
+		answer, err := prompt("Add a header, e.g. Accept: application/json (empty to finish)", "", validHeader)
+		if err != nil {
+			return nil, err
+		}
+		name, value, ok := parseHeader(answer)
+		if !ok {
+			break
+		}
+		result[name] = value
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// parseHeader splits "Name: value", reporting false for an empty answer
+func parseHeader(answer string) (string, string, bool) {
+	name, value, _ := strings.Cut(answer, ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(value), true
+}
+
+func validHeader(value string) error {
+	if strings.TrimSpace(value) != "" && !strings.Contains(value, ":") {
+		return errors.New("expected Name: value")
+	}
+	return nil
+}
+
+func required(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errors.New("a value is required")
+	}
+	return nil
+}
+
+// uniqueName validates names of new environments or routes
+func uniqueName(kind string, existing []string) func(string) error {
+	return func(value string) error {
+		if strings.TrimSpace(value) == "" {
+			return errors.New("a name is required")
+		}
+		if slices.Contains(existing, strings.TrimSpace(value)) {
+			return fmt.Errorf("%s %s already exists", kind, value)
+		}
+		return nil
+	}
+}
+
+// prompt asks for a line of text, pre-filled with value
+func prompt(label, value string, validate func(string) error) (string, error) {
+	input := promptui.Prompt{Label: label, Default: value, AllowEdit: true, Validate: validate}
+	answer, err := input.Run()
+	if err != nil {
+		return "", errCancelled
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+func selectItem(label string, items []string) (string, error) {
+	selectPrompt := promptui.Select{Label: label, Items: items, Size: 10}
+	_, item, err := selectPrompt.Run()
+	if err != nil {
+		return "", errCancelled
+	}
+	return item, nil
+}
+
+// confirm asks a yes/no question, defaulting to no
+func confirm(label string) bool {
+	input := promptui.Prompt{Label: label, IsConfirm: true}
+	_, err := input.Run()
+	return err == nil
+}
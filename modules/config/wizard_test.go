@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package config
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	hcconfig "github.com/pbidwell/hippocurl/internal/config"
+)
+
+func TestParseHeader(t *testing.T) {
+	name, value, ok := parseHeader(" Accept :  application/json ")
+	if !ok || name != "Accept" || value != "application/json" {
+		t.Errorf("unexpected header %q: %q (%v)", name, value, ok)
+	}
+	if _, _, ok := parseHeader("  "); ok {
+		t.Error("expected an empty answer to end the headers")
+	}
+	if validHeader("Accept") == nil || validHeader("X-Empty:") != nil || validHeader("") != nil {
+		t.Error("expected only answers without a colon to be rejected")
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	validate := uniqueName("route", []string{"ping", "create"})
+	if validate("ping ") == nil || validate(" ") == nil {
+		t.Error("expected existing and empty names to be rejected")
+	}
+	if err := validate("delete"); err != nil {
+		t.Errorf("expected a new name to be accepted, got %v", err)
+	}
+}
+
+const editedConfig = `# Team services
+services:
+  - name: Users
+    environments:
+      # Shared staging
+      - name: staging
+        base_url: https://staging.example.com
+        query: {api-version: 2}
+        timeout: 30s
+        variables:
+          tenant: acme
+    routes:
+      # Fetches a user
+      - name: get-user
+        method: GET
+        path: /users/{{id}}
+        query: {expand: profile}
+        retries: 2
+        retry_on: ["503"]
+        assert:
+          status: "200"
+`
+
+func TestWizardEditKeepsSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_config.yml")
+	if err := os.WriteFile(path, []byte(editedConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := &wizard{app: &hcconfig.App{Logger: log.New(io.Discard, "", 0)}, docs: map[string]*hcconfig.Document{}}
+	doc, err := hcconfig.LoadDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The settings the prompts ask for change, everything else is kept
+	env, err := doc.Environment("Users", "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.BaseURL = "https://staging2.example.com"
+	env.Headers = map[string]string{"Accept": "application/json"}
+	if err := w.saveEnvironment(doc, "Users", *env, false); err != nil {
+		t.Fatalf("unexpected error saving environment: %v", err)
+	}
+	route, err := doc.Route("Users", "get-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	route.Description = "Fetches one user"
+	route.Path = "/v2/users/{{id}}"
+	if err := w.saveRoute(doc, "Users", *route, false); err != nil {
+		t.Fatalf("unexpected error saving route: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{"# Team services", "# Shared staging", "# Fetches a user"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected the saved config to keep %q:\n%s", want, data)
+		}
+	}
+	saved, _ := hcconfig.LoadDocument(path)
+	env, _ = saved.Environment("Users", "staging")
+	if env.BaseURL != "https://staging2.example.com" || env.Query["api-version"][0] != "2" || env.Timeout != 30*time.Second || env.Variables["tenant"] != "acme" {
+		t.Errorf("environment settings not kept: %+v", env)
+	}
+	route, _ = saved.Route("Users", "get-user")
+	if route.Path != "/v2/users/{{id}}" || route.Query["expand"][0] != "profile" || route.Retries == nil || *route.Retries != 2 || route.Assert == nil || route.Assert.Status != "200" {
+		t.Errorf("route settings not kept: %+v", route)
+	}
+}
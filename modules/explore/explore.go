@@ -4,15 +4,18 @@ Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
 package explore
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/modules"
 	"github.com/pbidwell/hippocurl/utils"
 
 	"github.com/briandowns/spinner"
@@ -35,36 +38,45 @@ func (e ExploreModule) Use() string {
 	return fmt.Sprintf("%s <hostname>", e.Name())
 }
 
-func (e ExploreModule) Execute(app *config.App, args []string) {
+func (e ExploreModule) Execute(ctx context.Context, app *config.App, args []string) error {
 	utils.Print(e.Name(), utils.ModuleTitle)
 
 	if len(args) != 1 {
-		utils.Print("Usage: hc explore [hostname/IP]\n", utils.NormalText)
-		return
+		return modules.Usagef("usage: hc %s", e.Use())
 	}
 	host := args[0]
 	elogger = app.Logger
 
-	explore(host)
+	return explore(ctx, host)
 }
 
 func (e ExploreModule) Logo() string {
 	return "🔍"
 }
 
-func explore(host string) {
+// explore prints the DNS records and server scans of a host. It fails if
+// the host can't be resolved, and stops when ctx is cancelled.
+func explore(ctx context.Context, host string) error {
 	spinner := spinner.New(spinner.CharSets[35], 100*time.Millisecond)
 
 	// Fetch DNS records
 	utils.Print("DNS Records", utils.Header1)
 	spinner.Start()
-	dnsTable := fetchDNSRecords(host)
+	dnsTable := fetchDNSRecords(ctx, host)
 	spinner.Stop()
+	if ctx.Err() != nil {
+		elogger.Printf("Exploring %s cancelled during DNS lookups", host)
+		return ctx.Err()
+	}
 	dnsTable.Print()
 
-	ips, err := net.LookupIP(host)
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
 		elogger.Printf("Error resolving host: %v\n", err)
+		return modules.Exit(modules.ExitTransport, fmt.Errorf("resolving %s: %w", host, err))
 	}
 
 	filteredIPs := make([]net.IP, 0, len(ips)) // Pre-allocate capacity
@@ -90,16 +102,32 @@ func explore(host string) {
 	)
 
 	for _, ip := range filteredIPs {
-		geolocateIP(ip.String(), serverTbl)
+		if ctx.Err() != nil {
+			break
+		}
+		geolocateIP(ctx, ip.String(), serverTbl)
 	}
 
 	spinner.Stop()
+	if ctx.Err() != nil {
+		elogger.Printf("Exploring %s cancelled during server scans", host)
+		return ctx.Err()
+	}
 	serverTbl.Print()
+	return nil
 }
 
-func geolocateIP(ip string, serverTbl table.Table) {
+func geolocateIP(ctx context.Context, ip string, serverTbl table.Table) {
 	url := fmt.Sprintf("https://ipinfo.io/%s/json", ip)
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		fmt.Printf("Error creating geolocation request: %v\n", err)
+		return
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if ctx.Err() != nil {
+		return
+	}
 	if err != nil {
 		fmt.Printf("Error fetching geolocation data: %v\n", err)
 		return
@@ -112,8 +140,8 @@ func geolocateIP(ip string, serverTbl table.Table) {
 		return
 	}
 
-	openPorts := scanOpenPorts(ip)
-	issuer, expiry := fetchSSLCertificate(ip)
+	openPorts := scanOpenPorts(ctx, ip)
+	issuer, expiry := fetchSSLCertificate(ctx, ip)
 	serverTbl.AddRow(
 		ip,
 		fmt.Sprintf("%v", data["country"]),
@@ -128,17 +156,17 @@ func geolocateIP(ip string, serverTbl table.Table) {
 	)
 }
 
-func fetchDNSRecords(host string) table.Table {
+func fetchDNSRecords(ctx context.Context, host string) table.Table {
 	tbl := table.New("[CNAME]", "[NS Records]")
 
 	var cnameStr, nsStr string
 
-	cname, err := net.LookupCNAME(host)
+	cname, err := net.DefaultResolver.LookupCNAME(ctx, host)
 	if err == nil {
 		cnameStr = cname
 	}
 
-	nsRecords, err := net.LookupNS(host)
+	nsRecords, err := net.DefaultResolver.LookupNS(ctx, host)
 	if err == nil {
 		for _, ns := range nsRecords {
 			if nsStr == "" {
@@ -154,13 +182,14 @@ func fetchDNSRecords(host string) table.Table {
 	return tbl
 }
 
-func scanOpenPorts(host string) map[int]string {
+func scanOpenPorts(ctx context.Context, host string) map[int]string {
 	ports := []int{22, 80, 443, 115} // Common ports for SSH, HTTP, HTTPS, and SFTP
 	result := make(map[int]string)
+	dialer := &net.Dialer{Timeout: 100 * time.Millisecond}
 
 	for _, port := range ports {
-		address := fmt.Sprintf("%s:%d", host, port)
-		conn, err := net.DialTimeout("tcp", address, 100*time.Millisecond)
+		address := net.JoinHostPort(host, strconv.Itoa(port))
+		conn, err := dialer.DialContext(ctx, "tcp", address)
 		if err != nil {
 			result[port] = "Closed"
 		} else {
@@ -172,20 +201,21 @@ func scanOpenPorts(host string) map[int]string {
 	return result
 }
 
-func fetchSSLCertificate(host string) (string, string) {
+func fetchSSLCertificate(ctx context.Context, host string) (string, string) {
 	// Define TLS configuration to skip verification for IP addresses
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: net.ParseIP(host) != nil, // Skip verification only if it's an IP
 	}
 
-	conn, err := tls.Dial("tcp", fmt.Sprintf("%s:443", host), tlsConfig)
+	dialer := &tls.Dialer{Config: tlsConfig}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, "443"))
 	if err != nil {
 		elogger.Printf("Error fetching SSL Certificate for host %s: %v", host, err)
 		return "N/A", "N/A"
 	}
 	defer conn.Close()
 
-	cert := conn.ConnectionState().PeerCertificates[0]
+	cert := conn.(*tls.Conn).ConnectionState().PeerCertificates[0]
 	issuer := cert.Issuer.CommonName
 	expiry := cert.NotAfter.Format("2006-01-02 15:04:05")
 
@@ -0,0 +1,236 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package history
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	historystore "github.com/pbidwell/hippocurl/internal/history"
+	"github.com/pbidwell/hippocurl/modules"
+	"github.com/pbidwell/hippocurl/modules/api"
+	"github.com/pbidwell/hippocurl/utils"
+
+	"github.com/rodaine/table"
+)
+
+// HistoryModule implements the HippoModule interface
+type HistoryModule struct {
+	Filter Filter
+	Limit  int // Most recent entries listed, all if zero
+}
+
+// Filter selects history entries. Empty fields match everything.
+type Filter struct {
+	Service     string
+	Route       string
+	Environment string
+	Status      string // "200", "4xx" or "500-599"; "error" for calls without a response
+	Since       time.Time
+	Until       time.Time
+}
+
+// statusError is the Filter.Status matching calls that failed without a
+// response
+const statusError = "error"
+
+var hlogger *log.Logger
+
+func (h HistoryModule) Name() string {
+	return "history"
+}
+
+func (h HistoryModule) Description() string {
+	return "Lists, shows and re-runs previous API calls."
+}
+
+func (h HistoryModule) Use() string {
+	return fmt.Sprintf("%s [show <id> | rerun <id>]", h.Name())
+}
+
+func (h HistoryModule) Logo() string {
+	return "🕘"
+}
+
+func (h HistoryModule) Execute(ctx context.Context, app *config.App, args []string) error {
+	hlogger = app.Logger
+
+	var settings config.History
+	if app.APIConfig != nil {
+		settings = app.APIConfig.History
+	}
+	store := historystore.NewStore(filepath.Join(app.ConfigDir, historystore.FileName), settings)
+
+	if len(args) == 0 {
+		utils.Print(h.Name(), utils.ModuleTitle)
+		return h.list(store)
+	}
+	if len(args) != 2 || (args[0] != "show" && args[0] != "rerun") {
+		return modules.Usagef("usage: hc %s", h.Use())
+	}
+	id, err := strconv.Atoi(args[1])
+	if err != nil {
+		return modules.Usagef("invalid history id %q", args[1])
+	}
+	entry, err := store.Get(id)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return modules.Usagef("no history entry %d", id)
+	}
+
+	if args[0] == "rerun" {
+		hlogger.Printf("Re-running history entry %d: %s %s %s\n", entry.ID, entry.Service, entry.Route, entry.Environment)
+		module := api.APIModule{Overrides: api.Overrides{Variables: entry.Variables, Query: entry.Query}}
+		return module.Execute(ctx, app, []string{entry.Service, entry.Route, entry.Environment})
+	}
+	utils.Print(h.Name(), utils.ModuleTitle)
+	printEntry(entry)
+	return nil
+}
+
+func (h HistoryModule) list(store *historystore.Store) error {
+	if h.Filter.Status != "" && h.Filter.Status != statusError {
+		if _, err := api.StatusMatches(h.Filter.Status, 0); err != nil {
+			return modules.Usagef("--status: %v", err)
+		}
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		return err
+	}
+	var matched []historystore.Entry
+	for _, entry := range entries {
+		if h.Filter.Match(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	if h.Limit > 0 && len(matched) > h.Limit {
+		matched = matched[len(matched)-h.Limit:]
+	}
+	if len(matched) == 0 {
+		utils.Print("No matching history entries.", utils.NormalText)
+		return nil
+	}
+
+	tbl := table.New("[ID]", "[Time]", "[Service]", "[Route]", "[Environment]", "[Method]", "[Status]", "[Latency]")
+	slices.Reverse(matched)
+	for _, entry := range matched {
+		status, latency := statusError, "-"
+		if entry.Response != nil {
+			status = strconv.Itoa(entry.Response.StatusCode)
+		}
+		if entry.Timings != nil {
+			latency = formatMS(entry.Timings.TotalMS)
+		}
+		tbl.AddRow(entry.ID, entry.Time.Local().Format(time.DateTime), entry.Service, entry.Route, entry.Environment, entry.Request.Method, status, latency)
+	}
+	tbl.Print()
+
+	utils.Print(fmt.Sprintf("Use \"hc %s show %d\" for details or \"hc %s rerun %d\" to send it again.", h.Name(), matched[0].ID, h.Name(), matched[0].ID), utils.Hint)
+	return nil
+}
+
+// Match reports whether the filter selects entry
+func (f Filter) Match(entry historystore.Entry) bool {
+	switch {
+	case f.Service != "" && f.Service != entry.Service,
+		f.Route != "" && f.Route != entry.Route,
+		f.Environment != "" && f.Environment != entry.Environment,
+		!f.Since.IsZero() && entry.Time.Before(f.Since),
+		!f.Until.IsZero() && !entry.Time.Before(f.Until):
+		return false
+	}
+
+	switch {
+	case f.Status == "":
+		return true
+	case f.Status == statusError:
+		return entry.Response == nil
+	case entry.Response == nil:
+		return false
+	default:
+		ok, _ := api.StatusMatches(f.Status, entry.Response.StatusCode)
+		return ok
+	}
+}
+
+func printEntry(entry *historystore.Entry) {
+	utils.Print(fmt.Sprintf("History Entry %d", entry.ID), utils.Header1)
+	utils.PrintFieldValuePair("Time", entry.Time.Local().Format(time.DateTime))
+	utils.PrintFieldValuePair("Service", entry.Service)
+	utils.PrintFieldValuePair("Route", entry.Route)
+	utils.PrintFieldValuePair("Environment", entry.Environment)
+	for name, value := range entry.Variables {
+		utils.PrintFieldValuePair("Variable "+name, value)
+	}
+
+	utils.Print("HTTP Request", utils.Header1)
+	utils.Print("URL", utils.Header2)
+	utils.Print(entry.Request.Method+" "+entry.Request.URL, utils.NormalText)
+	utils.Print("Headers", utils.Header2)
+	utils.PrintHeaders(entry.Request.Headers)
+	utils.Print("Body", utils.Header2)
+	printBody(entry.Request.Body, entry.Request.Headers.Get("Content-Type"))
+
+	if entry.Error != "" {
+		utils.Print("Error", utils.Header1)
+		utils.Print(entry.Error, utils.NormalText)
+	}
+	if entry.Response != nil {
+		utils.Print("HTTP Response", utils.Header1)
+		utils.Print("Status", utils.Header2)
+		utils.Print(entry.Response.Status, utils.NormalText)
+		utils.Print("Headers", utils.Header2)
+		utils.PrintHeaders(entry.Response.Headers)
+		utils.Print("Body", utils.Header2)
+		printBody(entry.Response.Body, entry.Response.Headers.Get("Content-Type"))
+	}
+
+	if t := entry.Timings; t != nil {
+		utils.Print("Timing", utils.Header2)
+		utils.PrintFieldValuePair("DNS lookup", formatMS(t.DNSMS))
+		utils.PrintFieldValuePair("TCP connect", formatMS(t.ConnectMS))
+		utils.PrintFieldValuePair("TLS handshake", formatMS(t.TLSMS))
+		utils.PrintFieldValuePair("Wait (TTFB)", formatMS(t.WaitMS))
+		utils.PrintFieldValuePair("Content transfer", formatMS(t.TransferMS))
+		utils.PrintFieldValuePair("Total", formatMS(t.TotalMS))
+		connection := "new"
+		if t.Reused {
+			connection = "reused"
+		}
+		utils.PrintFieldValuePair("Connection", connection)
+		utils.PrintFieldValuePair("Protocol", t.Protocol)
+		if t.TLSVersion != "" {
+			utils.PrintFieldValuePair("TLS", t.TLSVersion)
+		}
+	}
+
+	utils.Print(fmt.Sprintf("Use \"hc history rerun %d\" to send this request again.", entry.ID), utils.Hint)
+}
+
+func printBody(body historystore.Body, contentType string) {
+	switch {
+	case body.Binary:
+		utils.Print(fmt.Sprintf("(%d bytes of binary data, not stored)", body.Size), utils.NormalText)
+		return
+	case body.Truncated:
+		api.PrintFormattedBody([]byte(body.Text), "")
+		utils.Print(fmt.Sprintf("(truncated, showing %d of %d bytes)", len(body.Text), body.Size), utils.NormalText)
+		return
+	}
+	api.PrintFormattedBody([]byte(body.Text), contentType)
+}
+
+func formatMS(ms float64) string {
+	return time.Duration(ms * float64(time.Millisecond)).Round(100 * time.Microsecond).String()
+}
@@ -0,0 +1,469 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package importer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+// curlRequest is the request described by a curl command line
+type curlRequest struct {
+	Method   string
+	URL      string
+	Headers  map[string]string
+	Data     []string
+	User     string
+	Get      bool     // -G: send data as query parameters
+	Warnings []string // Options that were ignored
+}
+
+// curl options taking a value that hc doesn't need
+var ignoredValueOptions = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true,
+	"--connect-timeout": true, "-w": true, "--write-out": true,
+	"--retry": true, "-x": true, "--proxy": true, "--cacert": true,
+	"-E": true, "--cert": true, "--key": true, "--resolve": true,
+}
+
+// curl options without a value that don't change the request
+var ignoredFlagOptions = map[string]bool{
+	"-s": true, "--silent": true, "-S": true, "--show-error": true,
+	"-L": true, "--location": true, "-k": true, "--insecure": true,
+	"-i": true, "--include": true, "-v": true, "--verbose": true,
+	"--compressed": true, "-f": true, "--fail": true, "-N": true,
+	"--no-buffer": true, "--http1.1": true, "--http2": true,
+	"-#": true, "--progress-bar": true,
+}
+
+// parseCurl parses the words of a curl command line, with or without the
+// leading "curl"
+func parseCurl(words []string) (*curlRequest, error) {
+	if len(words) > 0 && words[0] == "curl" {
+		words = words[1:]
+	}
+
+	req := &curlRequest{Headers: make(map[string]string)}
+	jsonData := false
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+
+		// value returns the option's argument, supporting "--opt=value" and "-Xvalue"
+		value := func() (string, error) {
+			if strings.HasPrefix(word, "--") {
+				if _, v, ok := strings.Cut(word, "="); ok {
+					return v, nil
+				}
+			} else if len(word) > 2 {
+				return word[2:], nil
+			}
+			if i+1 >= len(words) {
+				return "", fmt.Errorf("option %s requires a value", word)
+			}
+			i++
+			return words[i], nil
+		}
+		option := word
+		if strings.HasPrefix(word, "--") {
+			option, _, _ = strings.Cut(word, "=")
+		} else if strings.HasPrefix(word, "-") && len(word) > 2 {
+			option = word[:2]
+		}
+
+		switch {
+		case !strings.HasPrefix(word, "-") || word == "-":
+			if req.URL != "" {
+				return nil, fmt.Errorf("unexpected argument %q, URL already set to %s", word, req.URL)
+			}
+			req.URL = word
+			continue
+		case option == "--url":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			req.URL = v
+			continue
+		}
+
+		switch option {
+		case "-X", "--request":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			req.Method = strings.ToUpper(v)
+		case "-H", "--header":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			name, headerValue, ok := strings.Cut(v, ":")
+			if !ok {
+				req.Warnings = append(req.Warnings, fmt.Sprintf("ignored malformed header %q", v))
+				continue
+			}
+			req.Headers[http.CanonicalHeaderKey(strings.TrimSpace(name))] = strings.TrimSpace(headerValue)
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii", "--data-urlencode", "--json":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			if strings.HasPrefix(v, "@") && option != "--data-raw" {
+				req.Warnings = append(req.Warnings, fmt.Sprintf("data read from file %s was not imported", v[1:]))
+				continue
+			}
+			if option == "--data-urlencode" {
+				v = encodeDataURLEncode(v)
+			}
+			if option == "--json" {
+				jsonData = true
+			}
+			req.Data = append(req.Data, v)
+		case "-u", "--user":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			req.User = v
+		case "-G", "--get":
+			req.Get = true
+		case "-I", "--head":
+			req.Method = http.MethodHead
+		case "-A", "--user-agent":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			req.Headers["User-Agent"] = v
+		case "-e", "--referer":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			req.Headers["Referer"] = v
+		case "-b", "--cookie":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			if !strings.Contains(v, "=") {
+				req.Warnings = append(req.Warnings, fmt.Sprintf("cookies read from file %s were not imported", v))
+				continue
+			}
+			req.Headers["Cookie"] = v
+		default:
+			if ignoredValueOptions[option] {
+				if _, err := value(); err != nil {
+					return nil, err
+				}
+				req.Warnings = append(req.Warnings, fmt.Sprintf("ignored option %s", option))
+				continue
+			}
+			if !ignoredFlagOptions[word] && !isShortFlagCluster(word) {
+				req.Warnings = append(req.Warnings, fmt.Sprintf("ignored unknown option %s", word))
+			}
+		}
+	}
+
+	if req.URL == "" {
+		return nil, errors.New("no URL found in curl command")
+	}
+	if jsonData {
+		setDefault(req.Headers, "Content-Type", "application/json")
+		setDefault(req.Headers, "Accept", "application/json")
+	}
+	if len(req.Data) > 0 && !req.Get {
+		setDefault(req.Headers, "Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	return req, nil
+}
+
+// isShortFlagCluster reports whether word is a group of ignored short
+// flags such as "-sSL"
+func isShortFlagCluster(word string) bool {
+	if len(word) < 2 || word[0] != '-' || word[1] == '-' {
+		return false
+	}
+	for _, c := range word[1:] {
+		if !ignoredFlagOptions["-"+string(c)] {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeDataURLEncode applies curl's --data-urlencode rules for "content"
+// and "name=content"
+func encodeDataURLEncode(v string) string {
+	if name, content, ok := strings.Cut(v, "="); ok {
+		if name == "" {
+			return url.QueryEscape(content)
+		}
+		return name + "=" + url.QueryEscape(content)
+	}
+	return url.QueryEscape(v)
+}
+
+func setDefault(headers map[string]string, key, value string) {
+	if _, ok := headers[key]; !ok {
+		headers[key] = value
+	}
+}
+
+// toConfig converts the request into a route and the environment it runs
+// against, splitting the URL into base URL and path
+func (c *curlRequest) toConfig(routeName, envName string) (config.Route, config.Environment, error) {
+	rawURL := c.URL
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL // curl's default scheme
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return config.Route{}, config.Environment{}, fmt.Errorf("invalid URL %q: %w", c.URL, err)
+	}
+
+	env := config.Environment{
+		Name:    envName,
+		BaseURL: fmt.Sprintf("%s://%s", u.Scheme, u.Host),
+		Auth:    config.Auth{Type: "none"},
+	}
+
+	headers := make(map[string]string, len(c.Headers))
+	for key, value := range c.Headers {
+		headers[key] = value
+	}
+
+	user := c.User
+	if user == "" && u.User != nil {
+		user = u.User.String()
+	}
+	if user != "" {
+		username, password, _ := strings.Cut(user, ":")
+		env.Auth = config.Auth{Type: "basic", Username: username}
+		if password != "" {
+			env.Auth.Password = "{{password}}"
+		}
+	} else if auth, ok := headers["Authorization"]; ok {
+		if parsed, ok := parseAuthorization(auth); ok {
+			env.Auth = parsed
+			delete(headers, "Authorization")
+		}
+	}
+	if len(headers) > 0 {
+		env.Headers = headers
+	}
+
+	query := u.Query()
+	body := strings.Join(c.Data, "&")
+	if c.Get && body != "" {
+		dataQuery, err := url.ParseQuery(body)
+		if err != nil {
+			return config.Route{}, config.Environment{}, fmt.Errorf("parsing -G data as query: %w", err)
+		}
+		for key, values := range dataQuery {
+			query[key] = append(query[key], values...)
+		}
+		body = ""
+	}
+
+	method := c.Method
+	if method == "" {
+		method = http.MethodGet
+		if body != "" {
+			method = http.MethodPost
+		}
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if routeName == "" {
+		routeName = DefaultRouteName(method, path)
+	}
+
+	route := config.Route{
+		Name:   routeName,
+		Method: method,
+		Path:   path,
+		Body:   body,
+	}
+	if len(query) > 0 {
+		route.Query = query
+	}
+
+	return route, env, nil
+}
+
+// parseAuthorization converts Basic and Bearer Authorization headers to an
+// auth block. Credentials aren't written to the config: the token or password
+// becomes a {{token}} or {{password}} placeholder.
+func parseAuthorization(header string) (config.Auth, bool) {
+	scheme, credentials, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return config.Auth{}, false
+	}
+	credentials = strings.TrimSpace(credentials)
+
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		return config.Auth{Type: "bearer", Token: "{{token}}"}, true
+	case "basic":
+		decoded, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil {
+			return config.Auth{}, false
+		}
+		username, password, _ := strings.Cut(string(decoded), ":")
+		auth := config.Auth{Type: "basic", Username: username}
+		if password != "" {
+			auth.Password = "{{password}}"
+		}
+		return auth, true
+	}
+	return config.Auth{}, false
+}
+
+// authPlaceholder returns the placeholder toConfig wrote for the
+// credentials of auth, if any
+func authPlaceholder(auth config.Auth) string {
+	switch auth.Type {
+	case "bearer":
+		return auth.Token
+	case "basic":
+		return auth.Password
+	}
+	return ""
+}
+
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// DefaultRouteName derives a route name such as "get-users" from the
+// method and the last static path segment
+func DefaultRouteName(method, path string) string {
+	name := strings.ToLower(method)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := nonNameChars.ReplaceAllString(strings.ToLower(segments[i]), "-")
+		segment = strings.Trim(segment, "-")
+		if segment != "" {
+			return name + "-" + segment
+		}
+	}
+	return name + "-root"
+}
+
+// splitShellWords splits a command line the way a POSIX shell would,
+// handling quotes, escapes, line continuations and bash's $'...' strings
+func splitShellWords(line string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] != '\n' && runes[i] != '\r' {
+					current.WriteRune(runes[i])
+					inWord = true
+				} else if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+					i++
+				}
+			}
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end == -1 {
+				return nil, errors.New("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+			inWord = true
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			text, end, err := ansiCString(runes, i+2)
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(text)
+			i = end
+			inWord = true
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// ansiCString decodes a bash $'...' string starting after the opening
+// quote, returning the text and the index of the closing quote
+func ansiCString(runes []rune, start int) (string, int, error) {
+	escapes := map[rune]string{'n': "\n", 't': "\t", 'r': "\r", '\\': "\\", '\'': "'", '"': "\"", '0': "\x00"}
+
+	var b strings.Builder
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '\'':
+			return b.String(), i, nil
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				if replacement, ok := escapes[runes[i]]; ok {
+					b.WriteString(replacement)
+				} else {
+					b.WriteRune('\\')
+					b.WriteRune(runes[i])
+				}
+			}
+		default:
+			b.WriteRune(runes[i])
+		}
+	}
+	return "", 0, errors.New("unterminated $'...' string")
+}
@@ -0,0 +1,343 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/modules"
+	"github.com/pbidwell/hippocurl/utils"
+)
+
+// ImportModule implements the HippoModule interface
+type ImportModule struct {
+	Service   string // Service the imported routes are added to
+	RouteName string // Overrides the generated route name
+	EnvName   string // Environment the imported base URL is stored in
+
+	EnvironmentFiles []string // Postman environment exports to import
+}
+
+var ilogger *log.Logger
+
+func (i ImportModule) Name() string {
+	return "import"
+}
+
+func (i ImportModule) Description() string {
+	return "Imports requests from other tools into the API config file."
+}
+
+func (i ImportModule) Use() string {
+	return fmt.Sprintf("%s curl <command> | %s postman <collection.json> | %s openapi <spec>", i.Name(), i.Name(), i.Name())
+}
+
+func (i ImportModule) Logo() string {
+	return "📥"
+}
+
+func (i ImportModule) Execute(ctx context.Context, app *config.App, args []string) error {
+	utils.Print(i.Name(), utils.ModuleTitle)
+	ilogger = app.Logger
+
+	if len(args) == 0 {
+		return modules.Usagef("usage: hc %s", i.Use())
+	}
+
+	switch args[0] {
+	case "curl":
+		return i.importCurl(ctx, app, args[1:])
+	case "postman":
+		return i.importPostman(app, args[1:])
+	case "openapi":
+		return i.importOpenAPI(app, args[1:])
+	default:
+		return modules.Usagef("unknown import format %q", args[0])
+	}
+}
+
+func (i ImportModule) importCurl(ctx context.Context, app *config.App, args []string) error {
+	words, err := curlWords(args)
+	if err != nil {
+		return fmt.Errorf("reading curl command: %w", err)
+	}
+	if ctx.Err() != nil {
+		// Interrupted while reading stdin; don't import a partial command
+		return ctx.Err()
+	}
+
+	req, err := parseCurl(words)
+	if err != nil {
+		return fmt.Errorf("parsing curl command: %w", err)
+	}
+
+	envName := i.EnvName
+	if envName == "" {
+		envName = "default"
+	}
+	route, env, err := req.toConfig(i.RouteName, envName)
+	if err != nil {
+		return fmt.Errorf("converting curl command: %w", err)
+	}
+
+	doc, err := config.LoadDocument(app.ServiceFile(i.Service))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	if !doc.HasService(i.Service) {
+		if err := doc.AddService(config.Service{Name: i.Service}); err != nil {
+			return fmt.Errorf("adding service: %w", err)
+		}
+		utils.Print(fmt.Sprintf("Created service %s", i.Service), utils.NormalText)
+	}
+
+	if err := i.addEnvironment(doc, env); err != nil {
+		return fmt.Errorf("adding environment: %w", err)
+	}
+	if err := doc.AddRoute(i.Service, route); err != nil {
+		return fmt.Errorf("adding route: %w", err)
+	}
+	if err := doc.Save(); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+	ilogger.Printf("Imported curl command as route %s/%s into %s", i.Service, route.Name, doc.Path)
+
+	utils.Print("Imported Route", utils.Header1)
+	utils.PrintFieldValuePair("Route", route.Name)
+	utils.PrintFieldValuePair("Method", route.Method)
+	utils.PrintFieldValuePair("Base URL", env.BaseURL)
+	utils.PrintFieldValuePair("Path", route.Path)
+	utils.PrintFieldValuePair("Config", doc.Path)
+	printWarnings(req.Warnings)
+
+	if placeholder := authPlaceholder(env.Auth); placeholder != "" {
+		utils.Print(fmt.Sprintf("Credentials were saved as %s. Set them with --var, or as environment variables such as \"%s: ${secret:<name>}\".", placeholder, strings.Trim(placeholder, "{}")), utils.Hint)
+	}
+	utils.Print(fmt.Sprintf("Use \"hc api %s %s %s\" to send it.", i.Service, route.Name, env.Name), utils.Hint)
+	return nil
+}
+
+func (i ImportModule) importPostman(app *config.App, args []string) error {
+	if len(args) != 1 {
+		return modules.Usagef("usage: hc %s postman <collection.json>", i.Name())
+	}
+
+	collection, err := loadPostmanCollection(args[0])
+	if err != nil {
+		return fmt.Errorf("reading collection: %w", err)
+	}
+	var envs []*postmanEnvironment
+	for _, path := range i.EnvironmentFiles {
+		env, err := loadPostmanEnvironment(path)
+		if err != nil {
+			return fmt.Errorf("reading environment: %w", err)
+		}
+		envs = append(envs, env)
+	}
+
+	service, unsupported := convertPostman(collection, envs, i.Service)
+	if service.Name == "" {
+		return modules.Usagef("collection has no name, pass one with --service")
+	}
+
+	doc, err := config.LoadDocument(app.ServiceFile(service.Name))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	// New services are added whole; existing ones only gain the routes and
+	// environments they don't have yet
+	var skipped []string
+	addedRoutes, addedEnvs := len(service.Routes), len(service.Environments)
+	if !doc.HasService(service.Name) {
+		if err := doc.AddService(*service); err != nil {
+			return fmt.Errorf("adding service: %w", err)
+		}
+	} else {
+		addedRoutes, addedEnvs = 0, 0
+		for _, env := range service.Environments {
+			existing, err := doc.Environment(service.Name, env.Name)
+			if err != nil {
+				return fmt.Errorf("reading environment: %w", err)
+			}
+			if existing != nil {
+				skipped = append(skipped, fmt.Sprintf("environment %s already exists", env.Name))
+				continue
+			}
+			if err := doc.AddEnvironment(service.Name, env); err != nil {
+				return fmt.Errorf("adding environment: %w", err)
+			}
+			addedEnvs++
+		}
+		for _, route := range service.Routes {
+			existing, err := doc.Route(service.Name, route.Name)
+			if err != nil {
+				return fmt.Errorf("reading route: %w", err)
+			}
+			if existing != nil {
+				skipped = append(skipped, fmt.Sprintf("route %s already exists", route.Name))
+				continue
+			}
+			if err := doc.AddRoute(service.Name, route); err != nil {
+				return fmt.Errorf("adding route: %w", err)
+			}
+			addedRoutes++
+		}
+		if len(service.Variables) > 0 {
+			skipped = append(skipped, "collection variables (service already exists)")
+		}
+	}
+
+	if err := doc.Save(); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+	ilogger.Printf("Imported Postman collection %s as service %s into %s", args[0], service.Name, doc.Path)
+
+	utils.Print("Imported Collection", utils.Header1)
+	utils.PrintFieldValuePair("Service", service.Name)
+	utils.PrintFieldValuePair("Routes", fmt.Sprintf("%d", addedRoutes))
+	utils.PrintFieldValuePair("Environments", fmt.Sprintf("%d", addedEnvs))
+	utils.PrintFieldValuePair("Variables", fmt.Sprintf("%d", len(service.Variables)))
+	utils.PrintFieldValuePair("Config", doc.Path)
+
+	if len(skipped) > 0 {
+		utils.Print("Skipped", utils.Header2)
+		for _, item := range skipped {
+			utils.Print(item, utils.NormalText)
+		}
+	}
+	if len(unsupported) > 0 {
+		utils.Print("Not Imported", utils.Header2)
+		for _, item := range unsupported {
+			utils.Print(item, utils.NormalText)
+		}
+	}
+
+	if len(service.Routes) > 0 && len(service.Environments) > 0 {
+		name := service.Name
+		if strings.ContainsAny(name, " \t") {
+			name = fmt.Sprintf("'%s'", name)
+		}
+		utils.Print(fmt.Sprintf("Use \"hc api %s %s %s\" to send a request.", name, service.Routes[0].Name, service.Environments[0].Name), utils.Hint)
+	}
+	return nil
+}
+
+func (i ImportModule) importOpenAPI(app *config.App, args []string) error {
+	if len(args) != 1 {
+		return modules.Usagef("usage: hc %s openapi <spec>", i.Name())
+	}
+
+	spec, err := loadOpenAPI(args[0])
+	if err != nil {
+		return fmt.Errorf("reading OpenAPI document: %w", err)
+	}
+	service, unsupported := convertOpenAPI(spec, i.Service)
+	if service.Name == "" {
+		return modules.Usagef("document has no title, pass a service name with --service")
+	}
+
+	doc, err := config.LoadDocument(app.ServiceFile(service.Name))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	merge := &openAPIMerge{Added: len(service.Routes)}
+	if !doc.HasService(service.Name) {
+		err = doc.AddService(*service)
+	} else {
+		merge, err = mergeOpenAPI(doc, service)
+	}
+	if err != nil {
+		return fmt.Errorf("importing routes: %w", err)
+	}
+
+	if err := doc.Save(); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+	ilogger.Printf("Imported OpenAPI document %s as service %s into %s: %d added, %d updated, %d unchanged, %d skipped",
+		args[0], service.Name, doc.Path, merge.Added, merge.Updated, merge.Unchanged, len(merge.Skipped))
+
+	utils.Print("Imported OpenAPI Document", utils.Header1)
+	utils.PrintFieldValuePair("Service", service.Name)
+	utils.PrintFieldValuePair("Routes Added", fmt.Sprintf("%d", merge.Added))
+	utils.PrintFieldValuePair("Routes Updated", fmt.Sprintf("%d", merge.Updated))
+	utils.PrintFieldValuePair("Routes Unchanged", fmt.Sprintf("%d", merge.Unchanged))
+	utils.PrintFieldValuePair("Environments", fmt.Sprintf("%d", len(service.Environments)))
+	utils.PrintFieldValuePair("Config", doc.Path)
+
+	if len(merge.Skipped) > 0 {
+		utils.Print("Kept Hand-Edited Routes", utils.Header2)
+		for _, item := range merge.Skipped {
+			utils.Print(item, utils.NormalText)
+		}
+	}
+	if len(unsupported) > 0 {
+		utils.Print("Not Imported", utils.Header2)
+		for _, item := range unsupported {
+			utils.Print(item, utils.NormalText)
+		}
+	}
+	return nil
+}
+
+// addEnvironment adds the imported environment, reusing an existing
+// environment with the same name when it points at the same base URL
+func (i ImportModule) addEnvironment(doc *config.Document, env config.Environment) error {
+	existing, err := doc.Environment(i.Service, env.Name)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		if err := doc.AddEnvironment(i.Service, env); err != nil {
+			return err
+		}
+		utils.Print(fmt.Sprintf("Created environment %s", env.Name), utils.NormalText)
+		return nil
+	}
+
+	if existing.BaseURL != env.BaseURL {
+		return fmt.Errorf("environment %s already uses base URL %s, not %s; choose another environment with --env", env.Name, existing.BaseURL, env.BaseURL)
+	}
+	for key, value := range env.Headers {
+		if existing.Headers[key] != value {
+			utils.Print(fmt.Sprintf("Existing environment %s kept; header %s from the command was not applied", env.Name, key), utils.NormalText)
+		}
+	}
+	return nil
+}
+
+// curlWords returns the words of the curl command, given either as a
+// single quoted argument, as separate arguments or on stdin
+func curlWords(args []string) ([]string, error) {
+	switch len(args) {
+	case 0:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return splitShellWords(string(data))
+	case 1:
+		return splitShellWords(args[0])
+	default:
+		return args, nil
+	}
+}
+
+func printWarnings(warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	utils.Print("Warnings", utils.Header2)
+	for _, warning := range warnings {
+		utils.Print(strings.TrimSpace(warning), utils.NormalText)
+	}
+}
@@ -0,0 +1,559 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIMethods are the operations of a path item, in the order routes are
+// generated
+var openAPIMethods = []string{"get", "put", "post", "patch", "delete", "head", "options", "trace"}
+
+// openAPIPathParam matches "{id}" style path templates
+var openAPIPathParam = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// maxSchemaDepth bounds example generation for deeply nested or recursive
+// schemas
+const maxSchemaDepth = 8
+
+// openAPISpec is an OpenAPI 3 document, kept generic so that "$ref"
+// pointers can be followed anywhere in it
+type openAPISpec struct {
+	doc map[string]any
+}
+
+// openAPIImport accumulates the converted service and everything that
+// couldn't be converted
+type openAPIImport struct {
+	spec        *openAPISpec
+	service     config.Service
+	unsupported []string
+	routeNames  map[string]bool
+}
+
+// openAPIMerge counts what a re-import did to an existing service
+type openAPIMerge struct {
+	Added, Updated, Unchanged int
+	Skipped                   []string
+}
+
+func loadOpenAPI(path string) (*openAPISpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// JSON documents are valid YAML, so one parser handles both
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document %s: %w", path, err)
+	}
+	version := fmt.Sprint(doc["openapi"])
+	if !strings.HasPrefix(version, "3") {
+		if doc["swagger"] != nil {
+			return nil, fmt.Errorf("%s is a Swagger 2 document, only OpenAPI 3 is supported", path)
+		}
+		return nil, fmt.Errorf("%s is not an OpenAPI 3 document", path)
+	}
+	return &openAPISpec{doc: doc}, nil
+}
+
+// convertOpenAPI converts a spec into a service with one route per
+// operation and one environment per server
+func convertOpenAPI(spec *openAPISpec, serviceName string) (*config.Service, []string) {
+	if serviceName == "" {
+		serviceName = stringValue(mapValue(spec.doc["info"])["title"])
+	}
+	imp := &openAPIImport{
+		spec:       spec,
+		service:    config.Service{Name: serviceName},
+		routeNames: make(map[string]bool),
+	}
+
+	imp.convertServers()
+
+	paths := mapValue(spec.doc["paths"])
+	for _, path := range sortedKeys(paths) {
+		item := mapValue(spec.resolve(paths[path]))
+		shared := listValue(item["parameters"])
+		for _, method := range openAPIMethods {
+			if op := mapValue(item[method]); op != nil {
+				imp.convertOperation(path, method, op, shared)
+			}
+		}
+	}
+
+	for i := range imp.service.Routes {
+		imp.service.Routes[i].ImportHash = importHash(imp.service.Routes[i])
+	}
+	return &imp.service, imp.unsupported
+}
+
+// convertServers creates an environment per server, substituting the
+// defaults of server variables
+func (imp *openAPIImport) convertServers() {
+	names := make(map[string]bool)
+	for i, raw := range listValue(imp.spec.doc["servers"]) {
+		server := mapValue(raw)
+		baseURL := strings.TrimSuffix(stringValue(server["url"]), "/")
+		variables := mapValue(server["variables"])
+		baseURL = openAPIPathParam.ReplaceAllStringFunc(baseURL, func(match string) string {
+			if variable := mapValue(variables[match[1:len(match)-1]]); variable != nil {
+				return stringValue(variable["default"])
+			}
+			return match
+		})
+
+		name := slug(stringValue(server["description"]))
+		if name == "" {
+			if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+				name = slug(u.Hostname())
+			}
+		}
+		if name == "" {
+			name = fmt.Sprintf("server-%d", i+1)
+		}
+		unique := name
+		for n := 2; names[unique]; n++ {
+			unique = fmt.Sprintf("%s-%d", name, n)
+		}
+		names[unique] = true
+
+		if !strings.Contains(baseURL, "://") {
+			imp.unsupported = append(imp.unsupported, fmt.Sprintf("server %s: relative URL %q, set the environment's base_url to the full URL", unique, baseURL))
+		}
+		imp.service.Environments = append(imp.service.Environments, config.Environment{Name: unique, BaseURL: baseURL})
+	}
+
+	if len(imp.service.Environments) == 0 {
+		imp.service.Environments = []config.Environment{{Name: "default"}}
+		imp.unsupported = append(imp.unsupported, "document has no servers, set the default environment's base_url")
+	}
+}
+
+func (imp *openAPIImport) convertOperation(path, method string, op map[string]any, shared []any) {
+	label := fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+
+	name := stringValue(op["operationId"])
+	if name == "" {
+		name = DefaultRouteName(method, path)
+	}
+	unique := name
+	for n := 2; imp.routeNames[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", name, n)
+	}
+	imp.routeNames[unique] = true
+
+	route := config.Route{
+		Name:        unique,
+		Description: stringValue(op["summary"]),
+		Method:      strings.ToUpper(method),
+		Path:        openAPIPathParam.ReplaceAllString(path, "{{$1}}"),
+	}
+	if route.Description == "" {
+		route.Description = firstLine(stringValue(op["description"]))
+	}
+
+	for _, param := range imp.parameters(shared, listValue(op["parameters"])) {
+		name, in := stringValue(param["name"]), stringValue(param["in"])
+		required, _ := param["required"].(bool)
+		example, hasExample := imp.parameterExample(param)
+
+		switch in {
+		case "path":
+			if hasExample {
+				imp.setVariable(name, example)
+			}
+		case "query":
+			if !required {
+				continue
+			}
+			if route.Query == nil {
+				route.Query = make(map[string][]string)
+			}
+			if !hasExample {
+				example = "{{" + name + "}}"
+			}
+			route.Query[name] = []string{example}
+		case "header":
+			if !required {
+				continue
+			}
+			if route.Headers == nil {
+				route.Headers = make(map[string]string)
+			}
+			if !hasExample {
+				example = "{{" + name + "}}"
+			}
+			route.Headers[name] = example
+		case "cookie":
+			if required {
+				imp.unsupported = append(imp.unsupported, fmt.Sprintf("%s: cookie parameter %s", label, name))
+			}
+		}
+	}
+
+	if body := mapValue(imp.spec.resolve(op["requestBody"])); body != nil {
+		imp.convertBody(&route, body, label)
+	}
+
+	imp.service.Routes = append(imp.service.Routes, route)
+}
+
+// parameters merges path-level and operation-level parameters, the latter
+// overriding the former by name and location
+func (imp *openAPIImport) parameters(shared, own []any) []map[string]any {
+	var params []map[string]any
+	index := make(map[string]int)
+	for _, raw := range append(append([]any(nil), shared...), own...) {
+		param := mapValue(imp.spec.resolve(raw))
+		if param == nil {
+			continue
+		}
+		key := stringValue(param["in"]) + ":" + stringValue(param["name"])
+		if i, ok := index[key]; ok {
+			params[i] = param
+			continue
+		}
+		index[key] = len(params)
+		params = append(params, param)
+	}
+	return params
+}
+
+// parameterExample returns a parameter's example, from the parameter itself
+// or its schema
+func (imp *openAPIImport) parameterExample(param map[string]any) (string, bool) {
+	if example, ok := param["example"]; ok {
+		return scalarString(example), true
+	}
+	for _, raw := range mapValue(param["examples"]) {
+		if example, ok := mapValue(imp.spec.resolve(raw))["value"]; ok {
+			return scalarString(example), true
+		}
+	}
+	schema := mapValue(imp.spec.resolve(param["schema"]))
+	for _, key := range []string{"example", "default"} {
+		if example, ok := schema[key]; ok {
+			return scalarString(example), true
+		}
+	}
+	if enum := listValue(schema["enum"]); len(enum) > 0 {
+		return scalarString(enum[0]), true
+	}
+	return "", false
+}
+
+func (imp *openAPIImport) setVariable(name, value string) {
+	if imp.service.Variables == nil {
+		imp.service.Variables = make(map[string]string)
+	}
+	if _, exists := imp.service.Variables[name]; !exists {
+		imp.service.Variables[name] = value
+	}
+}
+
+// convertBody sets an example request body, preferring JSON content
+func (imp *openAPIImport) convertBody(route *config.Route, body map[string]any, label string) {
+	content := mapValue(body["content"])
+	contentType := ""
+	for _, candidate := range sortedKeys(content) {
+		if candidate == "application/json" || strings.HasSuffix(candidate, "+json") {
+			contentType = candidate
+			break
+		}
+	}
+	if contentType == "" && content["application/x-www-form-urlencoded"] != nil {
+		contentType = "application/x-www-form-urlencoded"
+	}
+	if contentType == "" {
+		if len(content) > 0 {
+			imp.unsupported = append(imp.unsupported, fmt.Sprintf("%s: %s request body", label, strings.Join(sortedKeys(content), ", ")))
+		}
+		return
+	}
+
+	media := mapValue(content[contentType])
+	example, ok := media["example"]
+	if !ok {
+		for _, name := range sortedKeys(mapValue(media["examples"])) {
+			if value, found := mapValue(imp.spec.resolve(mapValue(media["examples"])[name]))["value"]; found {
+				example, ok = value, true
+				break
+			}
+		}
+	}
+	if !ok {
+		example = imp.schemaExample(media["schema"], 0)
+	}
+	example = normalize(example)
+
+	if contentType == "application/x-www-form-urlencoded" {
+		values := url.Values{}
+		for key, value := range mapValue(example) {
+			values.Set(key, scalarString(value))
+		}
+		route.Body = values.Encode()
+	} else {
+		data, err := json.MarshalIndent(example, "", "  ")
+		if err != nil {
+			imp.unsupported = append(imp.unsupported, fmt.Sprintf("%s: request body example: %v", label, err))
+			return
+		}
+		route.Body = string(data)
+	}
+
+	if route.Headers == nil {
+		route.Headers = make(map[string]string)
+	}
+	route.Headers["Content-Type"] = contentType
+}
+
+// schemaExample builds an example value for a schema from its examples,
+// defaults and types
+func (imp *openAPIImport) schemaExample(raw any, depth int) any {
+	schema := mapValue(imp.spec.resolve(raw))
+	if schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+	for _, key := range []string{"example", "default", "const"} {
+		if value, ok := schema[key]; ok {
+			return value
+		}
+	}
+	if examples := listValue(schema["examples"]); len(examples) > 0 {
+		return examples[0]
+	}
+	if enum := listValue(schema["enum"]); len(enum) > 0 {
+		return enum[0]
+	}
+
+	if allOf := listValue(schema["allOf"]); len(allOf) > 0 {
+		merged := make(map[string]any)
+		for _, part := range allOf {
+			for key, value := range mapValue(imp.schemaExample(part, depth+1)) {
+				merged[key] = value
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options := listValue(schema[key]); len(options) > 0 {
+			return imp.schemaExample(options[0], depth+1)
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		object := make(map[string]any)
+		properties := mapValue(schema["properties"])
+		for name, property := range properties {
+			if readOnly, _ := mapValue(imp.spec.resolve(property))["readOnly"].(bool); readOnly {
+				continue
+			}
+			object[name] = imp.schemaExample(property, depth+1)
+		}
+		return object
+	case "array":
+		if item := imp.schemaExample(schema["items"], depth+1); item != nil {
+			return []any{item}
+		}
+		return []any{}
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "string":
+		switch stringValue(schema["format"]) {
+		case "date-time":
+			return "2025-01-01T00:00:00Z"
+		case "date":
+			return "2025-01-01"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		return "string"
+	}
+	return nil
+}
+
+// schemaType returns a schema's type, taking the first non-null type of
+// OpenAPI 3.1 type lists and inferring objects from their properties
+func schemaType(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, candidate := range t {
+			if s := stringValue(candidate); s != "null" {
+				return s
+			}
+		}
+	}
+	if schema["properties"] != nil {
+		return "object"
+	}
+	return ""
+}
+
+// resolve follows local "$ref" pointers such as
+// "#/components/schemas/User"
+func (s *openAPISpec) resolve(v any) any {
+	for i := 0; i < maxSchemaDepth; i++ {
+		ref := stringValue(mapValue(v)["$ref"])
+		if ref == "" {
+			return v
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil
+		}
+		var current any = s.doc
+		for _, part := range strings.Split(ref[2:], "/") {
+			part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+			current = mapValue(current)[part]
+		}
+		v = current
+	}
+	return nil
+}
+
+// mergeOpenAPI adds an imported service's routes and environments to an
+// existing service. Routes are only replaced when they are unchanged since
+// the previous import, so hand-edited routes are kept.
+func mergeOpenAPI(doc *config.Document, service *config.Service) (*openAPIMerge, error) {
+	merge := &openAPIMerge{}
+
+	for _, env := range service.Environments {
+		existing, err := doc.Environment(service.Name, env.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			continue
+		}
+		if err := doc.AddEnvironment(service.Name, env); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, route := range service.Routes {
+		existing, err := doc.Route(service.Name, route.Name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case existing == nil:
+			if err := doc.AddRoute(service.Name, route); err != nil {
+				return nil, err
+			}
+			merge.Added++
+		case existing.ImportHash == "":
+			merge.Skipped = append(merge.Skipped, fmt.Sprintf("route %s was not created by an import", route.Name))
+		case importHash(*existing) != existing.ImportHash:
+			merge.Skipped = append(merge.Skipped, fmt.Sprintf("route %s was edited since it was imported", route.Name))
+		case existing.ImportHash == route.ImportHash:
+			merge.Unchanged++
+		default:
+			if err := doc.SetRoute(service.Name, route); err != nil {
+				return nil, err
+			}
+			merge.Updated++
+		}
+	}
+	return merge, nil
+}
+
+// importHash fingerprints a route as written to the config file, ignoring
+// its own import_hash
+func importHash(route config.Route) string {
+	route.ImportHash = ""
+	data, err := yaml.Marshal(route)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// normalize converts the map[any]any values yaml produces for non-string
+// keys so that examples can be marshalled as JSON
+func normalize(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = normalize(item)
+		}
+		return value
+	case map[any]any:
+		converted := make(map[string]any, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(key)] = normalize(item)
+		}
+		return converted
+	case []any:
+		for i, item := range value {
+			value[i] = normalize(item)
+		}
+		return value
+	}
+	return v
+}
+
+func mapValue(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func listValue(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+func stringValue(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// scalarString formats an example value for a query string, header or
+// variable
+func scalarString(v any) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case nil:
+		return ""
+	case map[string]any, map[any]any, []any:
+		data, _ := json.Marshal(normalize(value))
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func slug(s string) string {
+	return strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
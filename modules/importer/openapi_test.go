@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package importer
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+func loadPetStore(t *testing.T) *config.Service {
+	t.Helper()
+	spec, err := loadOpenAPI("testdata/petstore.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service, _ := convertOpenAPI(spec, "")
+	return service
+}
+
+func TestConvertOpenAPI(t *testing.T) {
+	service := loadPetStore(t)
+	if service.Name != "PetStore" {
+		t.Errorf("expected the document title, got %s", service.Name)
+	}
+
+	if len(service.Environments) != 2 ||
+		service.Environments[0].Name != "production" || service.Environments[0].BaseURL != "https://eu.petstore.example.com/v1" ||
+		service.Environments[1].Name != "localhost" || service.Environments[1].BaseURL != "http://localhost:8080/v1" {
+		t.Errorf("unexpected environments: %+v", service.Environments)
+	}
+	if !reflect.DeepEqual(service.Variables, map[string]string{"petId": "7"}) {
+		t.Errorf("unexpected variables: %v", service.Variables)
+	}
+
+	routes := make(map[string]config.Route)
+	var names []string
+	for _, route := range service.Routes {
+		routes[route.Name] = route
+		names = append(names, route.Name)
+		if route.ImportHash == "" {
+			t.Errorf("route %s has no import hash", route.Name)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"listPets", "createPet", "showPetById", "delete-petid"}) {
+		t.Errorf("unexpected route names: %v", names)
+	}
+
+	list := routes["listPets"]
+	if list.Method != "GET" || list.Path != "/pets" || list.Description != "List all pets" {
+		t.Errorf("unexpected route: %+v", list)
+	}
+	if !reflect.DeepEqual(list.Query, map[string][]string{"limit": {"10"}}) {
+		t.Errorf("expected only the required query parameter, got %v", list.Query)
+	}
+
+	show := routes["showPetById"]
+	if show.Path != "/pets/{{petId}}" || show.Description != "Info for a specific pet." || show.Headers["X-Request-Id"] != "{{X-Request-Id}}" {
+		t.Errorf("unexpected route: %+v", show)
+	}
+
+	create := routes["createPet"]
+	var body map[string]any
+	if err := json.Unmarshal([]byte(create.Body), &body); err != nil {
+		t.Fatalf("invalid body %q: %v", create.Body, err)
+	}
+	want := map[string]any{"name": "hippo", "born": "2025-01-01", "tags": []any{"string"}}
+	if !reflect.DeepEqual(body, want) || create.Headers["Content-Type"] != "application/json" {
+		t.Errorf("unexpected body %v with headers %v", body, create.Headers)
+	}
+}
+
+func TestOpenAPIReimport(t *testing.T) {
+	doc, err := config.LoadDocument(filepath.Join(t.TempDir(), "api_config.yml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service := loadPetStore(t)
+	if err := doc.AddService(*service); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Edit one route by hand and replace another with a hand-written one
+	edited, _ := doc.Route("PetStore", "listPets")
+	edited.Query["limit"] = []string{"50"}
+	doc.SetRoute("PetStore", *edited)
+	doc.SetRoute("PetStore", config.Route{Name: "delete-petid", Method: "DELETE", Path: "/custom"})
+
+	// The spec changes every operation
+	for i := range service.Routes {
+		service.Routes[i].Description += " (v2)"
+		service.Routes[i].ImportHash = importHash(service.Routes[i])
+	}
+	added := config.Route{Name: "newOp", Method: "GET", Path: "/new"}
+	added.ImportHash = importHash(added)
+	service.Routes = append(service.Routes, added)
+
+	merge, err := mergeOpenAPI(doc, service)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if merge.Added != 1 || merge.Updated != 2 || merge.Unchanged != 0 || len(merge.Skipped) != 2 {
+		t.Errorf("unexpected merge result: %+v", merge)
+	}
+	if !strings.Contains(strings.Join(merge.Skipped, "\n"), "listPets was edited") {
+		t.Errorf("expected the edited route to be kept, got %v", merge.Skipped)
+	}
+
+	kept, _ := doc.Route("PetStore", "listPets")
+	if kept.Query["limit"][0] != "50" {
+		t.Errorf("hand-edited route was overwritten: %+v", kept)
+	}
+	updated, _ := doc.Route("PetStore", "createPet")
+	if !strings.HasSuffix(updated.Description, "(v2)") || importHash(*updated) != updated.ImportHash {
+		t.Errorf("expected the updated route to match its hash, got %+v", updated)
+	}
+
+	// Importing the same document again changes nothing
+	merge, _ = mergeOpenAPI(doc, service)
+	if merge.Added != 0 || merge.Updated != 0 || merge.Unchanged != 3 {
+		t.Errorf("unexpected merge result on re-import: %+v", merge)
+	}
+}
+
+func TestLoadOpenAPIRejectsSwagger(t *testing.T) {
+	if _, err := loadOpenAPI("testdata/users.postman_collection.json"); err == nil {
+		t.Errorf("expected error for a non-OpenAPI document")
+	}
+}
@@ -0,0 +1,477 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+// postmanCollection is the subset of the Postman v2.1 collection format
+// that can be expressed in the API config
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth"`
+	Variable []postmanVariable `json:"variable"`
+	Event    []postmanEvent    `json:"event"`
+}
+
+// postmanItem is either a folder (with Item) or a request
+type postmanItem struct {
+	Name     string          `json:"name"`
+	Item     []postmanItem   `json:"item"`
+	Request  *postmanRequest `json:"request"`
+	Event    []postmanEvent  `json:"event"`
+	Auth     *postmanAuth    `json:"auth"`
+	Response []any           `json:"response"`
+}
+
+type postmanRequest struct {
+	Method      string          `json:"method"`
+	Header      []postmanHeader `json:"header"`
+	Body        *postmanBody    `json:"body"`
+	URL         postmanURL      `json:"url"`
+	Auth        *postmanAuth    `json:"auth"`
+	Description any             `json:"description"`
+}
+
+type postmanHeader struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+type postmanBody struct {
+	Mode       string          `json:"mode"`
+	Raw        string          `json:"raw"`
+	URLEncoded []postmanHeader `json:"urlencoded"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+// postmanURL is given either as a plain string or as an object
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Query    []postmanHeader   `json:"query"`
+	Variable []postmanVariable `json:"variable"`
+}
+
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	type plain postmanURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Basic  []postmanVariable `json:"basic"`
+	Bearer []postmanVariable `json:"bearer"`
+}
+
+type postmanVariable struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Enabled  *bool  `json:"enabled"` // Used by environment files
+}
+
+type postmanEvent struct {
+	Listen string `json:"listen"`
+}
+
+// postmanEnvironment is a Postman environment export
+type postmanEnvironment struct {
+	Name   string            `json:"name"`
+	Values []postmanVariable `json:"values"`
+}
+
+// postmanImport accumulates the converted service and everything that
+// couldn't be converted
+type postmanImport struct {
+	service     config.Service
+	unsupported []string
+	origins     map[string]int
+	routeNames  map[string]bool
+}
+
+// postmanPathVariable matches ":id" style path variables
+var postmanPathVariable = regexp.MustCompile(`(^|/):([A-Za-z_][A-Za-z0-9_]*)`)
+
+func loadPostmanCollection(path string) (*postmanCollection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("parsing Postman collection %s: %w", path, err)
+	}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.1") {
+		return nil, fmt.Errorf("unsupported Postman collection schema %s, export the collection as v2.1", collection.Info.Schema)
+	}
+	return &collection, nil
+}
+
+func loadPostmanEnvironment(path string) (*postmanEnvironment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var env postmanEnvironment
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("parsing Postman environment %s: %w", path, err)
+	}
+	if env.Name == "" {
+		env.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &env, nil
+}
+
+// convertPostman converts a collection and its environments into a
+// service, returning the constructs that were not imported
+func convertPostman(collection *postmanCollection, envs []*postmanEnvironment, serviceName string) (*config.Service, []string) {
+	if serviceName == "" {
+		serviceName = collection.Info.Name
+	}
+	imp := &postmanImport{
+		service:    config.Service{Name: serviceName},
+		origins:    make(map[string]int),
+		routeNames: make(map[string]bool),
+	}
+
+	if vars := postmanVariables(collection.Variable); len(vars) > 0 {
+		imp.service.Variables = vars
+	}
+	if len(collection.Event) > 0 {
+		imp.unsupported = append(imp.unsupported, fmt.Sprintf("collection: %s", describeEvents(collection.Event)))
+	}
+
+	envAuth := config.Auth{Type: "none"}
+	if collection.Auth != nil {
+		if auth, ok := imp.convertAuth(collection.Auth, "collection"); ok && auth != nil {
+			envAuth = *auth
+		}
+	}
+
+	imp.convertItems(collection.Item, nil)
+
+	// Requests sharing a single origin get it as their environment's base
+	// URL; otherwise routes keep absolute URLs in their paths
+	baseURL := ""
+	if len(imp.origins) == 1 {
+		for origin := range imp.origins {
+			baseURL = origin
+		}
+		for i := range imp.service.Routes {
+			imp.service.Routes[i].Path = strings.TrimPrefix(imp.service.Routes[i].Path, baseURL)
+			if imp.service.Routes[i].Path == "" {
+				imp.service.Routes[i].Path = "/"
+			}
+		}
+	} else if len(imp.origins) > 1 {
+		imp.unsupported = append(imp.unsupported, "collection: requests use several hosts, so routes keep absolute URLs and environments have no base_url")
+	}
+
+	if len(envs) == 0 {
+		imp.service.Environments = []config.Environment{{Name: "default", BaseURL: baseURL, Auth: envAuth}}
+	}
+	for _, env := range envs {
+		environment := config.Environment{Name: env.Name, BaseURL: baseURL, Auth: envAuth}
+		if vars := postmanVariables(env.Values); len(vars) > 0 {
+			environment.Variables = vars
+		}
+		imp.service.Environments = append(imp.service.Environments, environment)
+	}
+
+	return &imp.service, imp.unsupported
+}
+
+// convertItems walks folders depth first, converting each request
+func (imp *postmanImport) convertItems(items []postmanItem, folders []string) {
+	for _, item := range items {
+		itemPath := append(append([]string(nil), folders...), item.Name)
+		label := strings.Join(itemPath, "/")
+
+		if len(item.Event) > 0 {
+			imp.unsupported = append(imp.unsupported, fmt.Sprintf("%s: %s", label, describeEvents(item.Event)))
+		}
+		if item.Request == nil {
+			if item.Auth != nil {
+				imp.unsupported = append(imp.unsupported, fmt.Sprintf("%s: folder-level auth", label))
+			}
+			imp.convertItems(item.Item, itemPath)
+			continue
+		}
+		imp.convertRequest(item, itemPath, label)
+	}
+}
+
+func (imp *postmanImport) convertRequest(item postmanItem, itemPath []string, label string) {
+	req := item.Request
+	route := config.Route{
+		Name:        imp.uniqueRouteName(itemPath),
+		Description: item.Name,
+		Method:      strings.ToUpper(req.Method),
+	}
+	if route.Method == "" {
+		route.Method = "GET"
+	}
+	if description, ok := req.Description.(string); ok && description != "" {
+		route.Description = fmt.Sprintf("%s - %s", item.Name, firstLine(description))
+	}
+
+	origin, path := splitPostmanURL(req.URL.Raw)
+	imp.origins[origin]++
+	route.Path = origin + postmanPathVariable.ReplaceAllString(path, "$1{{$2}}")
+
+	for _, variable := range req.URL.Variable {
+		if variable.Key == "" || postmanValue(variable.Value) == "" {
+			continue
+		}
+		if imp.service.Variables == nil {
+			imp.service.Variables = make(map[string]string)
+		}
+		if _, exists := imp.service.Variables[variable.Key]; !exists {
+			imp.service.Variables[variable.Key] = postmanValue(variable.Value)
+		}
+	}
+
+	query := make(map[string][]string)
+	for _, param := range req.URL.Query {
+		if !param.Disabled && param.Key != "" {
+			query[param.Key] = append(query[param.Key], param.Value)
+		}
+	}
+	if len(query) > 0 {
+		route.Query = query
+	}
+
+	headers := make(map[string]string)
+	for _, header := range req.Header {
+		if !header.Disabled && header.Key != "" {
+			headers[header.Key] = header.Value
+		}
+	}
+
+	if req.Body != nil {
+		body, contentType, ok := convertPostmanBody(req.Body)
+		if !ok {
+			imp.unsupported = append(imp.unsupported, fmt.Sprintf("%s: %s body", label, req.Body.Mode))
+		}
+		route.Body = body
+		if contentType != "" && !hasHeader(headers, "Content-Type") {
+			headers["Content-Type"] = contentType
+		}
+	}
+	if len(headers) > 0 {
+		route.Headers = headers
+	}
+
+	if req.Auth != nil {
+		if auth, ok := imp.convertAuth(req.Auth, label); ok {
+			route.Auth = auth
+		}
+	}
+	if len(item.Response) > 0 {
+		imp.unsupported = append(imp.unsupported, fmt.Sprintf("%s: %d saved example response(s)", label, len(item.Response)))
+	}
+
+	imp.service.Routes = append(imp.service.Routes, route)
+}
+
+// convertAuth converts basic, bearer and noauth blocks. Other auth types are
+// reported and ignored.
+func (imp *postmanImport) convertAuth(auth *postmanAuth, label string) (*config.Auth, bool) {
+	values := func(vars []postmanVariable) map[string]string {
+		m := make(map[string]string)
+		for _, v := range vars {
+			m[v.Key] = postmanValue(v.Value)
+		}
+		return m
+	}
+
+	switch auth.Type {
+	case "noauth":
+		return &config.Auth{Type: "none"}, true
+	case "basic":
+		basic := values(auth.Basic)
+		return &config.Auth{Type: "basic", Username: basic["username"], Password: basic["password"]}, true
+	case "bearer":
+		return &config.Auth{Type: "bearer", Token: values(auth.Bearer)["token"]}, true
+	case "inherit", "":
+		return nil, true
+	default:
+		imp.unsupported = append(imp.unsupported, fmt.Sprintf("%s: %s auth", label, auth.Type))
+		return nil, false
+	}
+}
+
+// uniqueRouteName builds a route name from the folder path and request name
+func (imp *postmanImport) uniqueRouteName(itemPath []string) string {
+	var parts []string
+	for _, part := range itemPath {
+		if s := slug(part); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	name := strings.Join(parts, "-")
+	if name == "" {
+		name = "request"
+	}
+
+	unique := name
+	for i := 2; imp.routeNames[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	imp.routeNames[unique] = true
+	return unique
+}
+
+// splitPostmanURL splits a raw Postman URL into its origin, either
+// "scheme://host" or a leading "{{variable}}", and the rest of the path
+// without the query string (which Postman also provides separately)
+func splitPostmanURL(raw string) (string, string) {
+	raw, _, _ = strings.Cut(strings.TrimSpace(raw), "?")
+
+	if strings.HasPrefix(raw, "{{") {
+		if end := strings.Index(raw, "}}"); end != -1 {
+			return raw[:end+2], raw[end+2:]
+		}
+	}
+
+	withScheme := raw
+	if !strings.Contains(withScheme, "://") {
+		withScheme = "http://" + withScheme
+	}
+	u, err := url.Parse(withScheme)
+	if err != nil || u.Host == "" {
+		return "", raw
+	}
+	origin := u.Scheme + "://" + u.Host
+	if !strings.Contains(raw, "://") {
+		origin = u.Host
+		return "http://" + origin, strings.TrimPrefix(raw, origin)
+	}
+	return origin, strings.TrimPrefix(raw, origin)
+}
+
+// convertPostmanBody returns the body text and the content type implied by
+// its mode. ok is false for modes that can't be represented.
+func convertPostmanBody(body *postmanBody) (string, string, bool) {
+	switch body.Mode {
+	case "raw":
+		contentType := ""
+		switch body.Options.Raw.Language {
+		case "json":
+			contentType = "application/json"
+		case "xml":
+			contentType = "application/xml"
+		}
+		return body.Raw, contentType, true
+	case "urlencoded":
+		values := url.Values{}
+		for _, field := range body.URLEncoded {
+			if !field.Disabled {
+				values.Add(field.Key, field.Value)
+			}
+		}
+		return values.Encode(), "application/x-www-form-urlencoded", true
+	case "graphql":
+		if body.GraphQL == nil {
+			return "", "", true
+		}
+		payload := map[string]any{"query": body.GraphQL.Query}
+		if strings.TrimSpace(body.GraphQL.Variables) != "" {
+			payload["variables"] = json.RawMessage(body.GraphQL.Variables)
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return "", "", false
+		}
+		return string(data), "application/json", true
+	case "":
+		return "", "", true
+	default:
+		return "", "", false
+	}
+}
+
+// postmanVariables returns the enabled variables as a map
+func postmanVariables(vars []postmanVariable) map[string]string {
+	values := make(map[string]string)
+	for _, v := range vars {
+		if v.Key == "" || v.Disabled || (v.Enabled != nil && !*v.Enabled) {
+			continue
+		}
+		values[v.Key] = postmanValue(v.Value)
+	}
+	return values
+}
+
+func postmanValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// describeEvents names the scripts attached to an item, e.g.
+// "prerequest and test scripts"
+func describeEvents(events []postmanEvent) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, event := range events {
+		if !seen[event.Listen] {
+			seen[event.Listen] = true
+			names = append(names, event.Listen)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 1 {
+		return names[0] + " script"
+	}
+	return strings.Join(names, " and ") + " scripts"
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvertPostman(t *testing.T) {
+	collection, err := loadPostmanCollection("testdata/users.postman_collection.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	env, err := loadPostmanEnvironment("testdata/dev.postman_environment.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	service, unsupported := convertPostman(collection, []*postmanEnvironment{env}, "")
+	if service.Name != "Users API" {
+		t.Errorf("expected the collection name, got %s", service.Name)
+	}
+	if !reflect.DeepEqual(service.Variables, map[string]string{"baseUrl": "https://api.example.com", "pageSize": "20", "id": "42"}) {
+		t.Errorf("unexpected variables: %v", service.Variables)
+	}
+
+	if len(service.Environments) != 1 {
+		t.Fatalf("expected one environment, got %+v", service.Environments)
+	}
+	dev := service.Environments[0]
+	if dev.Name != "dev" || dev.BaseURL != "{{baseUrl}}" || dev.Auth.Type != "bearer" || dev.Auth.Token != "{{token}}" {
+		t.Errorf("unexpected environment: %+v", dev)
+	}
+	if !reflect.DeepEqual(dev.Variables, map[string]string{"token": "dev-token"}) {
+		t.Errorf("unexpected environment variables: %v", dev.Variables)
+	}
+
+	var names []string
+	for _, route := range service.Routes {
+		names = append(names, route.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"users-get-user", "users-create-user", "users-upload-avatar", "get-user"}) {
+		t.Errorf("unexpected route names: %v", names)
+	}
+
+	get := service.Routes[0]
+	if get.Method != "GET" || get.Path != "/users/{{id}}" || get.Description != "Get User" {
+		t.Errorf("unexpected route: %+v", get)
+	}
+	if !reflect.DeepEqual(get.Query, map[string][]string{"expand": {"roles"}}) {
+		t.Errorf("unexpected query: %v", get.Query)
+	}
+	if !reflect.DeepEqual(get.Headers, map[string]string{"Accept": "application/json"}) {
+		t.Errorf("unexpected headers: %v", get.Headers)
+	}
+
+	create := service.Routes[1]
+	if create.Body != `{"name":"hippo"}` || create.Headers["Content-Type"] != "application/json" {
+		t.Errorf("unexpected body or headers: %q %v", create.Body, create.Headers)
+	}
+	if create.Auth == nil || create.Auth.Type != "basic" || create.Auth.Username != "admin" || create.Auth.Password != "secret" {
+		t.Errorf("unexpected route auth: %+v", create.Auth)
+	}
+
+	report := strings.Join(unsupported, "\n")
+	for _, want := range []string{"Users/Get User: test script", "Users/Upload Avatar: formdata body"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected %q to be reported, got:\n%s", want, report)
+		}
+	}
+}
+
+func TestConvertPostmanMixedHosts(t *testing.T) {
+	collection := &postmanCollection{}
+	collection.Info.Name = "Mixed"
+	collection.Item = []postmanItem{
+		{Name: "a", Request: &postmanRequest{Method: "GET", URL: postmanURL{Raw: "https://a.example.com/x"}}},
+		{Name: "b", Request: &postmanRequest{Method: "GET", URL: postmanURL{Raw: "https://b.example.com/y?z=1"}}},
+	}
+
+	service, unsupported := convertPostman(collection, nil, "Other")
+	if service.Name != "Other" || len(service.Environments) != 1 || service.Environments[0].Name != "default" || service.Environments[0].BaseURL != "" {
+		t.Errorf("unexpected service: %+v", service)
+	}
+	if service.Routes[0].Path != "https://a.example.com/x" || service.Routes[1].Path != "https://b.example.com/y" {
+		t.Errorf("expected absolute paths, got %s and %s", service.Routes[0].Path, service.Routes[1].Path)
+	}
+	if len(unsupported) != 1 || !strings.Contains(unsupported[0], "several hosts") {
+		t.Errorf("expected the mixed hosts to be reported, got %v", unsupported)
+	}
+}
@@ -2,6 +2,7 @@ package log
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 
@@ -24,7 +25,7 @@ func (l LogModule) Use() string {
 	return l.Name()
 }
 
-func (l LogModule) Execute(app *config.App, args []string) {
+func (l LogModule) Execute(ctx context.Context, app *config.App, args []string) error {
 	logFilePath := app.LogFilePath
 
 	utils.Print("Log File Location", utils.Header2)
@@ -32,8 +33,7 @@ func (l LogModule) Execute(app *config.App, args []string) {
 
 	file, err := os.Open(logFilePath)
 	if err != nil {
-		fmt.Printf("Error opening log file: %v\n", err)
-		return
+		return fmt.Errorf("opening log file: %w", err)
 	}
 	defer file.Close()
 
@@ -42,6 +42,7 @@ func (l LogModule) Execute(app *config.App, args []string) {
 	for _, line := range lines {
 		utils.Print(line, utils.NormalText)
 	}
+	return nil
 }
 
 func (l LogModule) Logo() string {
@@ -0,0 +1,293 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/vars"
+	"github.com/pbidwell/hippocurl/modules"
+	"github.com/pbidwell/hippocurl/utils"
+
+	"github.com/rodaine/table"
+)
+
+// MockModule implements the HippoModule interface
+type MockModule struct {
+	Addr string // Address to listen on, e.g. "127.0.0.1:8080"
+}
+
+var mlogger = log.New(io.Discard, "", 0)
+
+// mockRoute is a route served by the mock server
+type mockRoute struct {
+	Service string
+	Route   *config.Route
+	Method  string
+	Pattern *regexp.Regexp
+	Params  []string // Names of the path parameters, in the order of Pattern's groups
+	Dir     string   // Directory of the service's config file, if known
+}
+
+func (m MockModule) Name() string {
+	return "mock"
+}
+
+func (m MockModule) Description() string {
+	return "Serves the configured routes with canned responses from their mock blocks."
+}
+
+func (m MockModule) Use() string {
+	return fmt.Sprintf("%s [<serviceName>...]", m.Name())
+}
+
+func (m MockModule) Logo() string {
+	return "🎭"
+}
+
+func (m MockModule) Execute(ctx context.Context, app *config.App, args []string) error {
+	utils.Print(m.Name(), utils.ModuleTitle)
+	mlogger = app.Logger
+
+	services := app.APIConfig.Services
+	if len(args) > 0 {
+		services = nil
+		for _, name := range args {
+			service := app.APIConfig.GetServiceByName(name)
+			if service == nil {
+				return modules.Usagef("unknown service %q", name)
+			}
+			services = append(services, *service)
+		}
+	}
+
+	routes, err := buildRoutes(services)
+	if err != nil {
+		return err
+	}
+	if len(routes) == 0 {
+		return modules.Usagef("no routes to serve")
+	}
+
+	listener, err := net.Listen("tcp", m.Addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", m.Addr, err)
+	}
+	server := &http.Server{Handler: &handler{routes: routes, configDir: filepath.Dir(app.APIConfigPath)}}
+
+	utils.Print("Routes", utils.Header1)
+	tbl := table.New("[Method]", "[Path]", "[Service]", "[Route]", "[Status]")
+	for _, route := range routes {
+		status := "200"
+		if route.Route.Mock == nil {
+			status = "200 (no mock)"
+		} else if route.Route.Mock.Status != 0 {
+			status = fmt.Sprintf("%d", route.Route.Mock.Status)
+		}
+		tbl.AddRow(route.Method, pathOnly(route.Route.Path), route.Service, route.Route.Name, status)
+	}
+	tbl.Print()
+	utils.Print(fmt.Sprintf("Listening on http://%s", listener.Addr()), utils.Header2)
+	utils.Print("Press Ctrl-C to stop.", utils.Hint)
+	mlogger.Printf("Mock server listening on %s with %d routes", listener.Addr(), len(routes))
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving: %w", err)
+	}
+	return ctx.Err()
+}
+
+// buildRoutes compiles the routes of the services. Routes with fewer path
+// parameters are tried first, so /users/me wins over /users/{{id}}.
+func buildRoutes(services []config.Service) ([]*mockRoute, error) {
+	var routes []*mockRoute
+	for i := range services {
+		service := &services[i]
+		for j := range service.Routes {
+			route := &service.Routes[j]
+			pattern, params, err := compilePath(route.Path)
+			if err != nil {
+				return nil, fmt.Errorf("route %s/%s: %w", service.Name, route.Name, err)
+			}
+			method := strings.ToUpper(route.Method)
+			if method == "" {
+				method = http.MethodGet
+			}
+			mr := &mockRoute{Service: service.Name, Route: route, Method: method, Pattern: pattern, Params: params}
+			if service.File != "" {
+				mr.Dir = filepath.Dir(service.File)
+			}
+			routes = append(routes, mr)
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].Params) < len(routes[j].Params)
+	})
+	return routes, nil
+}
+
+// compilePath turns a route path into a pattern matching request paths,
+// where each {{name}} placeholder matches one path segment or part of it
+func compilePath(path string) (*regexp.Regexp, []string, error) {
+	path = pathOnly(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	var pattern strings.Builder
+	var params []string
+	pattern.WriteString("^")
+	last := 0
+	for _, match := range vars.Placeholder.FindAllStringSubmatchIndex(path, -1) {
+		pattern.WriteString(regexp.QuoteMeta(path[last:match[0]]))
+		pattern.WriteString("([^/]+)")
+		params = append(params, path[match[2]:match[3]])
+		last = match[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(path[last:]))
+	pattern.WriteString("/?$")
+
+	re, err := regexp.Compile(pattern.String())
+	return re, params, err
+}
+
+// pathOnly strips the query string from a route path
+func pathOnly(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	return path
+}
+
+// handler serves the mock routes
+type handler struct {
+	routes    []*mockRoute
+	configDir string // Directory body_file paths are relative to, unless the route's service has its own
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	route, params, allowed := h.match(r)
+
+	var status int
+	name := "-"
+	switch {
+	case route != nil:
+		name = route.Service + "/" + route.Route.Name
+		status = h.respond(w, r, route, params)
+	case len(allowed) > 0:
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		status = writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed for %s", r.Method, r.URL.Path))
+	default:
+		status = writeError(w, http.StatusNotFound, fmt.Sprintf("no route matches %s %s", r.Method, r.URL.Path))
+	}
+
+	line := fmt.Sprintf("%s %s %s -> %s %d (%s)", start.Format(time.TimeOnly), r.Method, r.URL.RequestURI(), name, status, time.Since(start).Round(time.Millisecond))
+	utils.Print(line, utils.NormalText)
+	mlogger.Printf("Mock %s", line)
+}
+
+// match finds the route for a request and its path parameters. When the
+// path matches routes of other methods only, their methods are returned.
+func (h *handler) match(r *http.Request) (*mockRoute, map[string]string, []string) {
+	var allowed []string
+	for _, route := range h.routes {
+		groups := route.Pattern.FindStringSubmatch(r.URL.Path)
+		if groups == nil {
+			continue
+		}
+		if route.Method != r.Method && !(r.Method == http.MethodHead && route.Method == http.MethodGet) {
+			allowed = append(allowed, route.Method)
+			continue
+		}
+		params := make(map[string]string, len(route.Params))
+		for i, name := range route.Params {
+			params[name] = groups[i+1]
+		}
+		return route, params, nil
+	}
+	return nil, nil, allowed
+}
+
+// respond writes the mock response, returning its status. Routes without a
+// mock block answer 200 with an empty body.
+func (h *handler) respond(w http.ResponseWriter, r *http.Request, route *mockRoute, params map[string]string) int {
+	mock := route.Route.Mock
+	if mock == nil {
+		w.WriteHeader(http.StatusOK)
+		return http.StatusOK
+	}
+
+	if mock.Latency > 0 {
+		timer := time.NewTimer(mock.Latency)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return http.StatusServiceUnavailable
+		}
+	}
+
+	body := mock.Body
+	if mock.BodyFile != "" {
+		path := mock.BodyFile
+		if !filepath.IsAbs(path) {
+			dir := h.configDir
+			if route.Dir != "" {
+				dir = route.Dir
+			}
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			mlogger.Printf("Error reading mock body file: %v", err)
+			return writeError(w, http.StatusInternalServerError, fmt.Sprintf("reading mock body file: %v", err))
+		}
+		body = string(data)
+	}
+	// Path parameters fill their placeholders; anything else is left as is
+	body = vars.NewResolver(params).Expander().Expand(body)
+
+	for name, value := range mock.Headers {
+		w.Header().Set(name, value)
+	}
+	if w.Header().Get("Content-Type") == "" && body != "" && json.Valid([]byte(body)) {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	status := mock.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write([]byte(body))
+	}
+	return status
+}
+
+// writeError writes a JSON error response, returning its status
+func writeError(w http.ResponseWriter, status int, message string) int {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	return status
+}
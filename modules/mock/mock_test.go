@@ -0,0 +1,75 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package mock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "users.json"), []byte(`[{"id":1}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := buildRoutes([]config.Service{{
+		Name: "Users",
+		Routes: []config.Route{
+			{Name: "get", Path: "/users/{{id}}?fields=all", Mock: &config.Mock{
+				Status: 201, Headers: map[string]string{"X-Mock": "yes"}, Body: `{"id":"{{id}}"}`, Latency: 20 * time.Millisecond,
+			}},
+			{Name: "me", Path: "/users/me", Mock: &config.Mock{Body: "me"}},
+			{Name: "list", Path: "users", Mock: &config.Mock{BodyFile: "users.json"}},
+			{Name: "delete", Method: "delete", Path: "/users/{{id}}"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := httptest.NewServer(&handler{routes: routes, configDir: dir})
+	defer server.Close()
+
+	cases := []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"GET", "/users/42", 201, `{"id":"42"}`},
+		{"GET", "/users/me", 200, "me"},
+		{"GET", "/users/", 200, `[{"id":1}]`},
+		{"DELETE", "/users/42", 200, ""},
+		{"PUT", "/users/42", 405, `{"error":"PUT is not allowed for /users/42"}` + "\n"},
+		{"GET", "/orders", 404, `{"error":"no route matches GET /orders"}` + "\n"},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, server.URL+c.path, nil)
+		start := time.Now()
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: unexpected error: %v", c.method, c.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != c.status || string(body) != c.body {
+			t.Errorf("%s %s: expected %d %q, got %d %q", c.method, c.path, c.status, c.body, resp.StatusCode, body)
+		}
+		if c.path == "/users/42" && c.method == "GET" {
+			if resp.Header.Get("X-Mock") != "yes" || resp.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("unexpected headers %v", resp.Header)
+			}
+			if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+				t.Errorf("expected the latency to be applied, took %s", elapsed)
+			}
+		}
+	}
+}
@@ -4,13 +4,71 @@ Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
 package modules
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/pbidwell/hippocurl/internal/config"
 )
 
+// HippoModule is a command of hc. Execute returns an error when the module
+// failed; an *ExitError selects the process exit status. ctx is cancelled
+// when the user interrupts hc, and modules should stop their work promptly.
 type HippoModule interface {
 	Name() string
 	Description() string
 	Logo() string
 	Use() string
-	Execute(config *config.App, args []string)
+	Execute(ctx context.Context, config *config.App, args []string) error
+}
+
+// Exit statuses of hc
+const (
+	ExitOK          = 0   // Success, including 1xx-3xx responses
+	ExitFailure     = 1   // Any error without a more specific status
+	ExitTransport   = 2   // No response was received: DNS, connection, TLS or timeout errors
+	ExitUsage       = 3   // Invalid arguments or unknown service, route or environment
+	ExitClientError = 4   // The server answered with a 4xx status
+	ExitServerError = 5   // The server answered with a 5xx status
+	ExitAssertion   = 6   // Assertions, tests or other checks failed
+	ExitInterrupted = 130 // Interrupted by SIGINT or SIGTERM, as shells report Ctrl-C
+)
+
+// ExitError is an error carrying the exit status hc should end with. Err
+// may be nil when the module already reported the failure itself.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Exit wraps err with an exit status
+func Exit(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+// Usagef returns an ExitUsage error
+func Usagef(format string, args ...any) error {
+	return Exit(ExitUsage, fmt.Errorf(format, args...))
+}
+
+// StatusExitCode maps an HTTP status to an exit status by its class
+func StatusExitCode(status int) int {
+	switch {
+	case status >= 500:
+		return ExitServerError
+	case status >= 400:
+		return ExitClientError
+	default:
+		return ExitOK
+	}
 }
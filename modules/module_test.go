@@ -0,0 +1,35 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestStatusExitCode(t *testing.T) {
+	cases := map[int]int{200: ExitOK, 204: ExitOK, 304: ExitOK, 404: ExitClientError, 429: ExitClientError, 503: ExitServerError}
+	for status, want := range cases {
+		if got := StatusExitCode(status); got != want {
+			t.Errorf("%d: expected exit status %d, got %d", status, want, got)
+		}
+	}
+}
+
+func TestExitErrorWrapping(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := fmt.Errorf("sending request: %w", Exit(ExitTransport, cause))
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != ExitTransport {
+		t.Fatalf("expected an ExitError with status %d, got %v", ExitTransport, err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("expected the cause to be unwrapped")
+	}
+	if Exit(ExitAssertion, nil).Error() != "exit status 6" {
+		t.Errorf("unexpected message for a silent exit: %s", Exit(ExitAssertion, nil))
+	}
+}
@@ -0,0 +1,513 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/modules"
+	"github.com/pbidwell/hippocurl/modules/importer"
+	"github.com/pbidwell/hippocurl/utils"
+)
+
+// RecordModule implements the HippoModule interface
+type RecordModule struct {
+	Service string // Service the recorded routes are added to
+	EnvName string // Environment holding the recorded base URL
+	Target  string // Base URL to forward to as a reverse proxy; empty to run a forward proxy
+	Addr    string // Address to listen on, e.g. "127.0.0.1:8081"
+	Mock    bool   // Also store the responses as mock blocks
+}
+
+var rlogger = log.New(io.Discard, "", 0)
+
+// maxRecordedBody is the largest request or response body stored in a route
+const maxRecordedBody = 64 * 1024
+
+// skippedHeaders aren't stored in recorded routes: they are set by the
+// client or the proxy, or hold credentials kept elsewhere
+var skippedHeaders = map[string]bool{
+	"Host": true, "User-Agent": true, "Accept-Encoding": true, "Content-Length": true,
+	"Connection": true, "Keep-Alive": true, "Te": true, "Trailer": true,
+	"Transfer-Encoding": true, "Upgrade": true, "Authorization": true, "Cookie": true,
+}
+
+// idSegment matches path segments that look like ids: numbers and UUIDs
+var idSegment = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})$`)
+
+func (r RecordModule) Name() string {
+	return "record"
+}
+
+func (r RecordModule) Description() string {
+	return "Runs a proxy that records the requests passing through it as routes."
+}
+
+func (r RecordModule) Use() string {
+	return r.Name()
+}
+
+func (r RecordModule) Logo() string {
+	return "⏺️"
+}
+
+func (r RecordModule) Execute(ctx context.Context, app *config.App, args []string) error {
+	utils.Print(r.Name(), utils.ModuleTitle)
+	rlogger = app.Logger
+
+	if r.Service == "" {
+		return modules.Usagef("a service is required")
+	}
+	rec := &recorder{module: r, seen: make(map[string]bool), names: make(map[string]bool), variables: make(map[string]bool)}
+	if r.Target != "" {
+		target, err := url.Parse(r.Target)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return modules.Usagef("invalid target %q, expected a URL such as https://api.example.com", r.Target)
+		}
+		rec.target = target
+	}
+
+	doc, err := config.LoadDocument(app.ServiceFile(r.Service))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	rec.doc = doc
+	if service := app.APIConfig.GetServiceByName(r.Service); service != nil {
+		for _, route := range service.Routes {
+			rec.seen[routeKey(route.Method, route.Path)] = true
+			rec.names[route.Name] = true
+		}
+	}
+	if rec.target != nil {
+		if err := rec.checkEnvironment(baseURL(rec.target)); err != nil {
+			return modules.Usagef("%v", err)
+		}
+	}
+
+	listener, err := net.Listen("tcp", r.Addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", r.Addr, err)
+	}
+	server := &http.Server{Handler: rec}
+
+	utils.Print("Recording", utils.Header1)
+	utils.PrintFieldValuePair("Service", r.Service)
+	utils.PrintFieldValuePair("Environment", r.EnvName)
+	utils.PrintFieldValuePair("Config", doc.Path)
+	if rec.target != nil {
+		utils.PrintFieldValuePair("Forwarding", fmt.Sprintf("http://%s -> %s", listener.Addr(), baseURL(rec.target)))
+		utils.Print(fmt.Sprintf("Point your client at http://%s instead of %s.", listener.Addr(), baseURL(rec.target)), utils.Hint)
+	} else {
+		utils.PrintFieldValuePair("Proxy", fmt.Sprintf("http://%s", listener.Addr()))
+		utils.Print(fmt.Sprintf("Set HTTP_PROXY=http://%s for your client. HTTPS requests can't be recorded by the forward proxy; use --target instead.", listener.Addr()), utils.Hint)
+	}
+	utils.Print("Requests", utils.Header2)
+	rlogger.Printf("Recording proxy listening on %s for service %s", listener.Addr(), r.Service)
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving: %w", err)
+	}
+
+	utils.Print(fmt.Sprintf("Recorded %d routes into %s", rec.recorded, r.Service), utils.Header2)
+	if len(rec.variables) > 0 {
+		names := make([]string, 0, len(rec.variables))
+		for name := range rec.variables {
+			names = append(names, "{{"+name+"}}")
+		}
+		sort.Strings(names)
+		utils.Print(fmt.Sprintf("Credentials were saved as %s. Set them with --var, or as environment variables such as \"%s: ${secret:<name>}\".", strings.Join(names, ", "), strings.Trim(names[0], "{}")), utils.Hint)
+	}
+	if rec.recorded > 0 {
+		utils.Print(fmt.Sprintf("Use \"hc api %s <route> %s\" to send them, or \"hc mock %s\" to serve them.", r.Service, r.EnvName, r.Service), utils.Hint)
+	}
+	return ctx.Err()
+}
+
+// recorder proxies requests and adds those it hasn't seen to the config
+type recorder struct {
+	module RecordModule
+	target *url.URL // Upstream of the reverse proxy, nil for a forward proxy
+
+	mu        sync.Mutex
+	doc       *config.Document
+	baseURL   string          // Base URL of the environment, once known
+	seen      map[string]bool // routeKey of every route of the service
+	names     map[string]bool // Route names used by the service
+	variables map[string]bool // Placeholders that replaced recorded credentials
+	recorded  int
+}
+
+// exchangeKey stores the request body read by ServeHTTP in the request
+// context, for ModifyResponse
+type exchangeKey struct{}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rec.target == nil && (r.Method == http.MethodConnect || !r.URL.IsAbs()) {
+		http.Error(w, "hc record: only plain HTTP requests sent through the proxy can be recorded; use --target for HTTPS", http.StatusNotImplemented)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("hc record: reading request: %v", err), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), exchangeKey{}, body))
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if rec.target != nil {
+				pr.SetURL(rec.target)
+			} else {
+				pr.Out.URL = pr.In.URL
+			}
+			// Uncompressed responses can be stored as mock bodies
+			pr.Out.Header.Del("Accept-Encoding")
+			pr.Out.Header.Del("Proxy-Connection")
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			respBody, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return err
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(respBody))
+			reqBody, _ := resp.Request.Context().Value(exchangeKey{}).([]byte)
+			rec.record(resp.Request, reqBody, resp, respBody)
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			utils.Print(fmt.Sprintf("%s %s: %v", r.Method, r.URL, err), utils.NormalText)
+			rlogger.Printf("Recording proxy error for %s %s: %v", r.Method, r.URL, err)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// record adds the route for an exchange unless the service already has one
+// with the same method and path
+func (rec *recorder) record(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	route, base, variables := recordedRoute(req, reqBody, rec.target)
+	outcome := rec.add(route, base, req.Header.Get("Authorization"), variables, resp, respBody)
+	utils.Print(fmt.Sprintf("%s %s %s -> %d, %s", time.Now().Format(time.TimeOnly), req.Method, req.URL.RequestURI(), resp.StatusCode, outcome), utils.NormalText)
+}
+
+// add saves a recorded route, returning what happened to it. variables are
+// the placeholders that replaced credentials in the route.
+func (rec *recorder) add(route config.Route, base, authorization string, variables []string, resp *http.Response, respBody []byte) string {
+	if rec.baseURL == "" {
+		if err := rec.checkEnvironment(base); err != nil {
+			return fmt.Sprintf("not recorded: %v", err)
+		}
+	}
+	if base != rec.baseURL {
+		return fmt.Sprintf("not recorded: only requests to %s are recorded", rec.baseURL)
+	}
+	key := routeKey(route.Method, route.Path)
+	if rec.seen[key] {
+		return "already recorded"
+	}
+
+	route.Name = rec.uniqueName(route.Name)
+	if rec.module.Mock {
+		route.Mock = recordedMock(resp, respBody)
+	}
+	authVariables, err := rec.save(route, authorization)
+	if err != nil {
+		rlogger.Printf("Error recording %s %s: %v", route.Method, route.Path, err)
+		return fmt.Sprintf("not recorded: %v", err)
+	}
+	for _, name := range append(variables, authVariables...) {
+		rec.variables[name] = true
+	}
+	rec.seen[key] = true
+	rec.names[route.Name] = true
+	rec.recorded++
+	rlogger.Printf("Recorded %s %s as route %s/%s", route.Method, route.Path, rec.module.Service, route.Name)
+	return "recorded as " + route.Name
+}
+
+// checkEnvironment makes sure the environment can hold base as its base URL,
+// remembering it as the base URL of recorded requests
+func (rec *recorder) checkEnvironment(base string) error {
+	if !rec.doc.HasService(rec.module.Service) {
+		rec.baseURL = base
+		return nil
+	}
+	existing, err := rec.doc.Environment(rec.module.Service, rec.module.EnvName)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.BaseURL != base {
+		return fmt.Errorf("environment %s already uses base URL %s, not %s; choose another environment with --env", rec.module.EnvName, existing.BaseURL, base)
+	}
+	rec.baseURL = base
+	return nil
+}
+
+// save adds a route to the config file, creating the service and the
+// environment on the first recording. The Authorization header of that
+// request becomes the environment's auth, with its credentials replaced by
+// placeholders, which are returned.
+func (rec *recorder) save(route config.Route, authorization string) ([]string, error) {
+	if !rec.doc.HasService(rec.module.Service) {
+		if err := rec.doc.AddService(config.Service{Name: rec.module.Service}); err != nil {
+			return nil, err
+		}
+	}
+	existing, err := rec.doc.Environment(rec.module.Service, rec.module.EnvName)
+	if err != nil {
+		return nil, err
+	}
+	var variables []string
+	if existing == nil {
+		env := config.Environment{Name: rec.module.EnvName, BaseURL: rec.baseURL, Auth: config.Auth{Type: "none"}}
+		if auth, names, ok := recordedAuth(authorization); ok {
+			env.Auth, variables = auth, names
+		}
+		if err := rec.doc.AddEnvironment(rec.module.Service, env); err != nil {
+			return nil, err
+		}
+	}
+	if err := rec.doc.AddRoute(rec.module.Service, route); err != nil {
+		return nil, err
+	}
+	return variables, rec.doc.Save()
+}
+
+// recordedAuth converts Basic and Bearer Authorization headers to an auth
+// block, with the token or password replaced by a placeholder
+func recordedAuth(header string) (config.Auth, []string, bool) {
+	scheme, credentials, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return config.Auth{}, nil, false
+	}
+
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		return config.Auth{Type: "bearer", Token: "{{token}}"}, []string{"token"}, true
+	case "basic":
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+		if err != nil {
+			return config.Auth{}, nil, false
+		}
+		username, _, _ := strings.Cut(string(decoded), ":")
+		return config.Auth{Type: "basic", Username: username, Password: "{{password}}"}, []string{"password"}, true
+	}
+	return config.Auth{}, nil, false
+}
+
+// uniqueName returns name, or name with a number appended if the service
+// already has a route called name
+func (rec *recorder) uniqueName(name string) string {
+	unique := name
+	for n := 2; rec.names[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", name, n)
+	}
+	return unique
+}
+
+// recordedRoute converts a proxied request to a route, returning it with
+// the base URL it was sent to. Path segments that look like ids become
+// {{id}}, {{id2}}... placeholders, and the values of secret-looking headers,
+// query parameters and body fields become placeholders named after them,
+// which are returned too.
+func recordedRoute(req *http.Request, body []byte, target *url.URL) (config.Route, string, []string) {
+	path := req.URL.EscapedPath()
+	if target != nil {
+		// The request went to the target's path prefix plus the client's path
+		path = "/" + strings.TrimPrefix(strings.TrimPrefix(path, strings.TrimSuffix(target.EscapedPath(), "/")), "/")
+	}
+
+	segments := strings.Split(path, "/")
+	ids := 0
+	endsWithID := false
+	for i, segment := range segments {
+		endsWithID = false
+		if idSegment.MatchString(segment) {
+			ids++
+			segments[i] = "{{id}}"
+			if ids > 1 {
+				segments[i] = fmt.Sprintf("{{id%d}}", ids)
+			}
+			endsWithID = true
+		}
+	}
+	path = strings.Join(segments, "/")
+	if path == "" {
+		path = "/"
+	}
+
+	name := ""
+	if endsWithID {
+		name = importer.DefaultRouteName(req.Method, strings.Join(segments[:len(segments)-1], "/")) + "-by-id"
+	} else {
+		name = importer.DefaultRouteName(req.Method, path)
+	}
+
+	variables := make(map[string]bool)
+	replace := func(key string) string {
+		name := placeholderName(key)
+		variables[name] = true
+		return "{{" + name + "}}"
+	}
+
+	route := config.Route{Name: name, Method: req.Method, Path: path}
+	if query := req.URL.Query(); len(query) > 0 {
+		for key, values := range query {
+			if utils.IsSensitiveKey(key) {
+				for i := range values {
+					values[i] = replace(key)
+				}
+			}
+		}
+		route.Query = query
+	}
+	for key, values := range req.Header {
+		if skippedHeaders[key] || strings.HasPrefix(key, "Proxy-") || strings.HasPrefix(key, "X-Forwarded-") || len(values) == 0 {
+			continue
+		}
+		if route.Headers == nil {
+			route.Headers = make(map[string]string)
+		}
+		route.Headers[key] = values[0]
+		if utils.IsSensitiveKey(key) {
+			route.Headers[key] = replace(key)
+		}
+	}
+	if len(body) <= maxRecordedBody && utf8.Valid(body) {
+		route.Body = string(replaceSecrets(body, req.Header.Get("Content-Type"), replace))
+	}
+
+	base := req.URL.Scheme + "://" + req.URL.Host
+	if target != nil {
+		base = baseURL(target)
+	}
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return route, base, names
+}
+
+var nonVariableChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// placeholderName turns a header, query parameter or field name into a
+// variable name, e.g. X-Api-Key into x_api_key
+func placeholderName(key string) string {
+	name := strings.Trim(nonVariableChars.ReplaceAllString(strings.ToLower(key), "_"), "_")
+	if name == "" {
+		return "secret"
+	}
+	return name
+}
+
+// replaceSecrets returns body with the values of secret-looking fields of
+// JSON and form bodies replaced by replace(field)
+func replaceSecrets(body []byte, contentType string, replace func(key string) string) []byte {
+	switch {
+	case strings.Contains(contentType, "json"):
+		var value any
+		if json.Unmarshal(body, &value) != nil || !replaceJSONSecrets(value, replace) {
+			return body
+		}
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(value); err != nil {
+			return body
+		}
+		return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	case strings.Contains(contentType, "x-www-form-urlencoded"):
+		// Rebuilt by hand, as encoding the values would escape placeholders
+		pairs := strings.Split(string(body), "&")
+		for i, pair := range pairs {
+			key, _, _ := strings.Cut(pair, "=")
+			if name, err := url.QueryUnescape(key); err == nil && utils.IsSensitiveKey(name) {
+				pairs[i] = key + "=" + replace(name)
+			}
+		}
+		return []byte(strings.Join(pairs, "&"))
+	}
+	return body
+}
+
+// replaceJSONSecrets replaces the secret-looking fields of a decoded JSON
+// value in place, reporting whether it changed anything
+func replaceJSONSecrets(value any, replace func(key string) string) bool {
+	changed := false
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if _, isString := field.(string); isString && utils.IsSensitiveKey(key) {
+				value[key] = replace(key)
+				changed = true
+				continue
+			}
+			changed = replaceJSONSecrets(field, replace) || changed
+		}
+	case []any:
+		for _, item := range value {
+			changed = replaceJSONSecrets(item, replace) || changed
+		}
+	}
+	return changed
+}
+
+// recordedMock converts a response to a mock block, redacting secret-looking
+// body fields such as tokens. Bodies that are binary or too large are left
+// out.
+func recordedMock(resp *http.Response, body []byte) *config.Mock {
+	mock := &config.Mock{Status: resp.StatusCode}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		mock.Headers = map[string]string{"Content-Type": contentType}
+	}
+	if len(body) <= maxRecordedBody && utf8.Valid(body) {
+		mock.Body = string(replaceSecrets(body, contentType, func(string) string { return utils.Redacted }))
+	}
+	return mock
+}
+
+// baseURL returns the scheme, host and path of a target URL
+func baseURL(target *url.URL) string {
+	return strings.TrimSuffix(fmt.Sprintf("%s://%s%s", target.Scheme, target.Host, target.EscapedPath()), "/")
+}
+
+func routeKey(method, path string) string {
+	if method == "" {
+		method = http.MethodGet
+	}
+	return strings.ToUpper(method) + " " + path
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package record
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+func TestRecordedRoute(t *testing.T) {
+	target, _ := url.Parse("https://api.example.com/v1")
+	req := httptest.NewRequest("PUT", "https://api.example.com/v1/users/42/orders/123e4567-e89b-12d3-a456-426614174000?full=true", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer abc")
+	req.Header.Set("User-Agent", "client/1.0")
+
+	route, base, _ := recordedRoute(req, []byte(`{"a":1}`), target)
+	if base != "https://api.example.com/v1" {
+		t.Errorf("expected the target as base URL, got %q", base)
+	}
+	if route.Path != "/users/{{id}}/orders/{{id2}}" || route.Name != "put-orders-by-id" {
+		t.Errorf("unexpected path %q or name %q", route.Path, route.Name)
+	}
+	if route.Query["full"][0] != "true" || route.Body != `{"a":1}` {
+		t.Errorf("unexpected query %v or body %q", route.Query, route.Body)
+	}
+	if len(route.Headers) != 1 || route.Headers["Content-Type"] != "application/json" {
+		t.Errorf("expected only the Content-Type header, got %v", route.Headers)
+	}
+}
+
+func TestRecordedRouteReplacesCredentials(t *testing.T) {
+	req := httptest.NewRequest("POST", "http://api.example.com/login?api_key=k1&page=2", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", "k2")
+
+	route, _, variables := recordedRoute(req, []byte(`{"user":"hippo","password":"p<w>"}`), nil)
+	if got := route.Query["api_key"][0]; got != "{{api_key}}" || route.Query["page"][0] != "2" {
+		t.Errorf("unexpected query %v", route.Query)
+	}
+	if got := route.Headers["X-Api-Key"]; got != "{{x_api_key}}" {
+		t.Errorf("expected the API key header to be a placeholder, got %q", got)
+	}
+	if want := `{"password":"{{password}}","user":"hippo"}`; route.Body != want {
+		t.Errorf("expected body %s, got %s", want, route.Body)
+	}
+	if strings.Join(variables, ",") != "api_key,password,x_api_key" {
+		t.Errorf("unexpected variables %v", variables)
+	}
+
+	form := httptest.NewRequest("POST", "http://api.example.com/login", nil)
+	form.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	route, _, _ = recordedRoute(form, []byte("user=hippo&client_secret=s%3D1"), nil)
+	if route.Body != "user=hippo&client_secret={{client_secret}}" {
+		t.Errorf("unexpected form body %q", route.Body)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	rlogger = log.New(io.Discard, "", 0)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"path":"`+r.URL.Path+`","access_token":"t0k3n"}`)
+	}))
+	defer upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "api_config.yml")
+	if err := os.WriteFile(path, []byte("# Services\nservices: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := config.LoadDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, _ := url.Parse(upstream.URL)
+	rec := &recorder{
+		module:    RecordModule{Service: "Users", EnvName: "recorded", Mock: true},
+		target:    target,
+		doc:       doc,
+		seen:      map[string]bool{},
+		names:     map[string]bool{},
+		variables: map[string]bool{},
+	}
+	proxy := httptest.NewServer(rec)
+	defer proxy.Close()
+
+	for _, p := range []string{"/users/1", "/users/2", "/users"} {
+		req, _ := http.NewRequest("GET", proxy.URL+p, nil)
+		req.Header.Set("Authorization", "Bearer s3cr3t")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated || !strings.Contains(string(body), p) {
+			t.Fatalf("%s: unexpected proxied response %d %s", p, resp.StatusCode, body)
+		}
+	}
+	if rec.recorded != 2 {
+		t.Errorf("expected 2 recorded routes, got %d", rec.recorded)
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Services", "name: get-users-by-id", "path: /users/{{id}}", "name: get-users\n", "base_url: " + upstream.URL, "type: bearer", "token: '{{token}}'", "status: 201"} {
+		if !strings.Contains(string(saved), want) {
+			t.Errorf("expected saved config to contain %q:\n%s", want, saved)
+		}
+	}
+	for _, secret := range []string{"s3cr3t", "t0k3n"} {
+		if strings.Contains(string(saved), secret) {
+			t.Errorf("expected %q to be left out of the saved config:\n%s", secret, saved)
+		}
+	}
+	if !rec.variables["token"] {
+		t.Errorf("expected the token placeholder to be reported, got %v", rec.variables)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package secret
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/secrets"
+	"github.com/pbidwell/hippocurl/modules"
+	"github.com/pbidwell/hippocurl/utils"
+
+	"github.com/manifoldco/promptui"
+)
+
+// SecretModule implements the HippoModule interface
+type SecretModule struct{}
+
+func (s SecretModule) Name() string {
+	return "secret"
+}
+
+func (s SecretModule) Description() string {
+	return "Manages the encrypted secret store referenced as ${secret:name} in the config."
+}
+
+func (s SecretModule) Use() string {
+	return fmt.Sprintf("%s set <name> [value] | get <name> | list | rm <name>", s.Name())
+}
+
+func (s SecretModule) Logo() string {
+	return "🔐"
+}
+
+// ChecksConfig lets the module run when the API config can't be read, as
+// secrets don't depend on it
+func (s SecretModule) ChecksConfig() {}
+
+func (s SecretModule) Execute(ctx context.Context, app *config.App, args []string) error {
+	path := filepath.Join(app.ConfigDir, secrets.StoreFileName)
+
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		utils.Print(s.Name(), utils.ModuleTitle)
+		return s.list(path)
+	case len(args) == 2 && args[0] == "get":
+		store, err := openStore(path, false)
+		if err != nil {
+			return err
+		}
+		value, ok := store.Values[args[1]]
+		if !ok {
+			return modules.Usagef("secret %q not found", args[1])
+		}
+		fmt.Println(value)
+		return nil
+	case len(args) == 2 && args[0] == "rm":
+		utils.Print(s.Name(), utils.ModuleTitle)
+		store, err := openStore(path, false)
+		if err != nil {
+			return err
+		}
+		if _, ok := store.Values[args[1]]; !ok {
+			return modules.Usagef("secret %q not found", args[1])
+		}
+		delete(store.Values, args[1])
+		if err := store.Save(); err != nil {
+			return err
+		}
+		app.Logger.Printf("Removed secret %s", args[1])
+		utils.Print(fmt.Sprintf("Removed secret %s", args[1]), utils.NormalText)
+		return nil
+	case (len(args) == 2 || len(args) == 3) && args[0] == "set":
+		utils.Print(s.Name(), utils.ModuleTitle)
+		return s.set(app, path, args[1], args[2:])
+	default:
+		return modules.Usagef("usage: hc %s", s.Use())
+	}
+}
+
+func (s SecretModule) list(path string) error {
+	if !secrets.StoreExists(path) {
+		utils.Print("No secrets stored.", utils.NormalText)
+		return nil
+	}
+	store, err := openStore(path, false)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(store.Values))
+	for name := range store.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		utils.Print("No secrets stored.", utils.NormalText)
+		return nil
+	}
+	for _, name := range names {
+		utils.Print(name, utils.NormalText)
+	}
+	return nil
+}
+
+// set stores a secret, asking for its value when it isn't given so it
+// doesn't end up in the shell history
+func (s SecretModule) set(app *config.App, path, name string, value []string) error {
+	if name == "" || name != strings.TrimSpace(name) || strings.Contains(name, "}") {
+		return modules.Usagef("invalid secret name %q", name)
+	}
+	store, err := openStore(path, !secrets.StoreExists(path))
+	if err != nil {
+		return err
+	}
+
+	if len(value) == 1 {
+		store.Values[name] = value[0]
+	} else {
+		input := promptui.Prompt{Label: fmt.Sprintf("Value of %s", name), Mask: '*'}
+		answer, err := input.Run()
+		if err != nil {
+			return modules.Exit(modules.ExitUsage, nil)
+		}
+		store.Values[name] = answer
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	app.Logger.Printf("Saved secret %s to %s", name, path)
+	utils.Print(fmt.Sprintf("Saved secret %s", name), utils.NormalText)
+	utils.Print(fmt.Sprintf("Reference it in the API config as ${secret:%s}.", name), utils.Hint)
+	return nil
+}
+
+// openStore asks for the passphrase and decrypts the store. With create
+// set, the passphrase is confirmed as it encrypts a new store.
+func openStore(path string, create bool) (*secrets.Store, error) {
+	if !create && !secrets.StoreExists(path) {
+		return nil, modules.Usagef("no secrets stored, add one with \"hc secret set <name>\"")
+	}
+	passphrase, err := secrets.ReadPassphrase(create)
+	if err != nil {
+		return nil, err
+	}
+	return secrets.OpenStore(path, passphrase)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes the test cases as a JUnit XML report. Requests that
+// got no response are reported as errors, failed assertions as failures.
+func writeJUnit(path string, service *config.Service, env *config.Environment, cases []testCase) error {
+	suite := junitTestSuite{
+		Name:      fmt.Sprintf("%s (%s)", service.Name, env.Name),
+		Tests:     len(cases),
+		Timestamp: time.Now().Format("2006-01-02T15:04:05"),
+	}
+
+	var total time.Duration
+	for _, c := range cases {
+		total += c.Duration
+		tc := junitTestCase{
+			Name:      c.Route,
+			ClassName: fmt.Sprintf("%s.%s", service.Name, env.Name),
+			Time:      seconds(c.Duration),
+		}
+
+		switch {
+		case c.Err != nil:
+			suite.Errors++
+			tc.Error = &junitMessage{Message: c.Err.Error(), Text: c.Err.Error()}
+		case !c.passed():
+			suite.Failures++
+			var lines []string
+			for _, result := range c.Results {
+				if !result.Passed {
+					lines = append(lines, fmt.Sprintf("%s: %s", result.Check, result.Detail))
+				}
+			}
+			tc.Failure = &junitMessage{Message: c.details(), Text: strings.Join(lines, "\n")}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = seconds(total)
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
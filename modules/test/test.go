@@ -0,0 +1,208 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/vars"
+	"github.com/pbidwell/hippocurl/modules"
+	"github.com/pbidwell/hippocurl/modules/api"
+	"github.com/pbidwell/hippocurl/utils"
+
+	"github.com/briandowns/spinner"
+	"github.com/rodaine/table"
+)
+
+// TestModule implements the HippoModule interface
+type TestModule struct {
+	// JUnitPath, when set, is where a JUnit XML report is written
+	JUnitPath string
+	Overrides api.Overrides
+}
+
+var tlogger *log.Logger
+
+// defaultAssertion applies to routes without an assert block
+var defaultAssertion = &config.Assertion{Status: "200-399"}
+
+// testCase is the outcome of running one route
+type testCase struct {
+	Route    string
+	Response *api.Response
+	Results  []api.AssertionResult
+	Err      error // Set when no response was received
+	Duration time.Duration
+}
+
+func (c testCase) passed() bool {
+	return c.Err == nil && api.AssertionsPassed(c.Results)
+}
+
+func (t TestModule) Name() string {
+	return "test"
+}
+
+func (t TestModule) Description() string {
+	return "Runs every route of a service against an environment and checks the route assertions."
+}
+
+func (t TestModule) Use() string {
+	return fmt.Sprintf("%s <serviceName> <environmentName>", t.Name())
+}
+
+func (t TestModule) Logo() string {
+	return "🧪"
+}
+
+func (t TestModule) Execute(ctx context.Context, app *config.App, args []string) error {
+	utils.Print(t.Name(), utils.ModuleTitle)
+
+	if len(args) != 2 {
+		return modules.Usagef("usage: hc %s", t.Use())
+	}
+	tlogger = app.Logger
+	api.Setup(app)
+
+	service := app.APIConfig.GetServiceByName(args[0])
+	if service == nil {
+		return modules.Usagef("unknown service %q", args[0])
+	}
+	env := service.GetEnvironmentByName(args[1])
+	if env == nil {
+		return modules.Usagef("unknown environment %q for service %s", args[1], service.Name)
+	}
+
+	store, err := vars.LoadStore(filepath.Join(app.ConfigDir, vars.StoreFileName), service.Name, env.Name)
+	if err != nil {
+		return fmt.Errorf("loading variable store: %w", err)
+	}
+
+	utils.Print(fmt.Sprintf("%s (%s)", service.Name, env.Name), utils.Header1)
+	spinner := spinner.New(spinner.CharSets[35], 100*time.Millisecond)
+	spinner.Start()
+	cases := runSuite(ctx, service, env, t.Overrides, store)
+	spinner.Stop()
+	if ctx.Err() != nil {
+		tlogger.Printf("Test run of %s in %s cancelled after %d routes", service.Name, env.Name, len(cases))
+		return ctx.Err()
+	}
+
+	failed := printResults(cases)
+
+	if t.JUnitPath != "" {
+		if err := writeJUnit(t.JUnitPath, service, env, cases); err != nil {
+			return fmt.Errorf("writing JUnit report: %w", err)
+		}
+		utils.Print(fmt.Sprintf("JUnit report written to %s", t.JUnitPath), utils.NormalText)
+	}
+
+	if failed > 0 {
+		return modules.Exit(modules.ExitAssertion, nil)
+	}
+	return nil
+}
+
+// runSuite sends every route of the service in order, so routes extracting
+// values can feed the routes after them. It stops early when ctx is
+// cancelled.
+func runSuite(ctx context.Context, service *config.Service, env *config.Environment, overrides api.Overrides, store *vars.Store) []testCase {
+	cases := make([]testCase, 0, len(service.Routes))
+
+	for i := range service.Routes {
+		route := &service.Routes[i]
+		start := time.Now()
+		c := runRoute(ctx, service, route, env, overrides, store)
+		if ctx.Err() != nil {
+			break
+		}
+		c.Duration = time.Since(start)
+
+		if c.passed() {
+			tlogger.Printf("Test %s/%s in %s passed", service.Name, route.Name, env.Name)
+		} else {
+			tlogger.Printf("Test %s/%s in %s failed: %s", service.Name, route.Name, env.Name, c.details())
+		}
+		cases = append(cases, c)
+	}
+
+	return cases
+}
+
+func runRoute(ctx context.Context, service *config.Service, route *config.Route, env *config.Environment, overrides api.Overrides, store *vars.Store) testCase {
+	c := testCase{Route: route.Name}
+
+	req, err := api.PrepareRequest(service, route, env, overrides, store.Values)
+	if err != nil {
+		c.Err = err
+		return c
+	}
+
+	resp, err := api.Send(ctx, req)
+	if err != nil {
+		c.Err = err
+		return c
+	}
+	c.Response = resp
+
+	if len(route.Extract) > 0 {
+		_, errs := api.ApplyExtractions(route.Extract, resp, store)
+		for _, err := range errs {
+			tlogger.Printf("Error extracting variable for %s: %v", route.Name, err)
+		}
+	}
+
+	assertion := route.Assert
+	if assertion == nil {
+		assertion = defaultAssertion
+	}
+	c.Results = api.CheckAssertions(assertion, resp)
+	return c
+}
+
+// details describes why a test case failed
+func (c testCase) details() string {
+	if c.Err != nil {
+		return c.Err.Error()
+	}
+	var failures []string
+	for _, result := range c.Results {
+		if !result.Passed {
+			failures = append(failures, fmt.Sprintf("%s: %s", result.Check, result.Detail))
+		}
+	}
+	return strings.Join(failures, "; ")
+}
+
+// printResults prints the results table and summary, returning the number
+// of failed test cases
+func printResults(cases []testCase) int {
+	tbl := table.New("[Route]", "[Status]", "[Latency]", "[Result]", "[Details]")
+
+	failed := 0
+	for _, c := range cases {
+		status, latency := "-", "-"
+		if c.Response != nil {
+			status = fmt.Sprintf("%d", c.Response.StatusCode)
+			latency = c.Response.Latency.Round(time.Millisecond).String()
+		}
+
+		result := "PASS"
+		if !c.passed() {
+			result = "FAIL"
+			failed++
+		}
+		tbl.AddRow(c.Route, status, latency, result, c.details())
+	}
+	tbl.Print()
+
+	utils.Print(fmt.Sprintf("%d passed, %d failed", len(cases)-failed, failed), utils.Header2)
+	return failed
+}
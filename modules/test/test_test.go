@@ -0,0 +1,92 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package test
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pbidwell/hippocurl/internal/config"
+	"github.com/pbidwell/hippocurl/internal/vars"
+	"github.com/pbidwell/hippocurl/modules/api"
+)
+
+func TestRunSuite(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"session": "s-1"}`))
+		case "/profile/s-1":
+			w.Write([]byte(`{"name": "hippo"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	tlogger = log.New(io.Discard, "", 0)
+	api.Setup(&config.App{Logger: tlogger, ConfigDir: dir})
+
+	service := &config.Service{
+		Name: "Users",
+		Routes: []config.Route{
+			{
+				Name: "login", Method: "POST", Path: "/login",
+				Extract: []config.Extraction{{Name: "session", JSONPath: "$.session"}},
+				Assert:  &config.Assertion{Status: "200", Headers: map[string]string{"Content-Type": "application/json"}},
+			},
+			{
+				Name: "profile", Method: "GET", Path: "/profile/{{session}}",
+				Assert: &config.Assertion{JSON: map[string]any{"$.name": "rhino"}},
+			},
+			{Name: "missing", Method: "GET", Path: "/missing"},
+		},
+	}
+	env := &config.Environment{Name: "local", BaseURL: server.URL}
+	store, _ := vars.LoadStore(filepath.Join(dir, vars.StoreFileName), service.Name, env.Name)
+
+	cases := runSuite(context.Background(), service, env, api.Overrides{}, store)
+	if len(cases) != 3 {
+		t.Fatalf("expected 3 test cases, got %d", len(cases))
+	}
+	if !cases[0].passed() {
+		t.Errorf("expected login to pass: %s", cases[0].details())
+	}
+	if cases[1].passed() || cases[1].Response == nil || cases[1].Response.StatusCode != http.StatusOK {
+		t.Errorf("expected profile to reach the server with the extracted session and fail its assertion: %+v", cases[1])
+	}
+	if cases[2].passed() {
+		t.Errorf("expected missing route to fail the default status assertion")
+	}
+
+	reportPath := filepath.Join(dir, "report.xml")
+	if err := writeJUnit(reportPath, service, env, cases); err != nil {
+		t.Fatalf("unexpected error writing JUnit report: %v", err)
+	}
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("report is not valid XML: %v", err)
+	}
+	suite := report.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 2 || suite.Errors != 0 {
+		t.Errorf("unexpected suite counts: tests=%d failures=%d errors=%d", suite.Tests, suite.Failures, suite.Errors)
+	}
+	if suite.Cases[0].Failure != nil || suite.Cases[1].Failure == nil {
+		t.Errorf("unexpected test case failures: %+v", suite.Cases)
+	}
+}
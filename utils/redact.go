@@ -0,0 +1,28 @@
+/*
+Copyright © 2025 Pablo Bidwell <bidwell.pablo@gmail.com>
+*/
+package utils
+
+import "strings"
+
+// Redacted replaces sensitive values in output
+const Redacted = "REDACTED"
+
+// sensitiveKeyParts are substrings of header, query and field names whose
+// values are treated as secrets
+var sensitiveKeyParts = []string{
+	"authorization", "cookie", "token", "secret", "password", "passwd",
+	"api-key", "api_key", "apikey", "x-api-key", "session", "signature",
+}
+
+// IsSensitiveKey reports whether a header, query parameter or field name
+// usually carries a secret
+func IsSensitiveKey(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}